@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/civo/civogo/utils"
 )
@@ -126,31 +125,10 @@ func (c *Client) FindApplication(search string) (*Application, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Application{}
-
-	for _, value := range apps.Items {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(apps.Items, search, c.FindMode,
+		func(a Application) string { return a.ID },
+		func(a Application) []string { return []string{a.Name} },
+	)
 }
 
 // CreateApplication creates a new application
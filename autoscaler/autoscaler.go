@@ -0,0 +1,340 @@
+// Package autoscaler scales the node pools of a Civo Kubernetes cluster to the
+// size reported by a pluggable signal source, within per-pool bounds and cooldowns.
+package autoscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/civo/civogo"
+)
+
+// Recommendation is what a Signal wants for a pool
+type Recommendation struct {
+	// Nodes is how many nodes the pool should have, before Min and Max are applied
+	Nodes int
+	// RemovalCandidates are instance IDs to remove first when scaling down, such
+	// as the least busy nodes, most preferred first
+	RemovalCandidates []string
+	// Reason is reported in the Decision, such as "cpu at 85%"
+	Reason string
+}
+
+// Signal recommends the size of a pool, for example from metrics or pending pods
+type Signal interface {
+	Recommend(ctx context.Context, clusterID string, pool civogo.KubernetesPool) (Recommendation, error)
+}
+
+// SignalFunc lets an ordinary function be used as a Signal
+type SignalFunc func(ctx context.Context, clusterID string, pool civogo.KubernetesPool) (Recommendation, error)
+
+// Recommend calls f
+func (f SignalFunc) Recommend(ctx context.Context, clusterID string, pool civogo.KubernetesPool) (Recommendation, error) {
+	return f(ctx, clusterID, pool)
+}
+
+// PoolPolicy bounds the scaling of one pool, pools without a policy are left alone
+type PoolPolicy struct {
+	PoolID string
+	Min    int
+	Max    int
+	// ScaleUpCooldown is how long after scaling up before the pool can scale up again
+	ScaleUpCooldown time.Duration
+	// ScaleDownCooldown is how long after scaling either way before the pool can scale down
+	ScaleDownCooldown time.Duration
+}
+
+// Action is what a Decision does to a pool
+type Action string
+
+// Actions in a Decision
+const (
+	ActionNone      Action = "none"
+	ActionScaleUp   Action = "scale-up"
+	ActionScaleDown Action = "scale-down"
+)
+
+// Decision is the outcome of checking one pool
+type Decision struct {
+	PoolID  string
+	Action  Action
+	Current int
+	// Recommended is the signal's node count and Target is after Min, Max and cooldowns
+	Recommended int
+	Target      int
+	// RemoveInstances are the instances removed by a scale-down
+	RemoveInstances []string
+	Reason          string
+	// Applied is false for dry runs and decisions with no action
+	Applied bool
+}
+
+// Autoscaler scales a cluster's pools, it's safe to use from multiple goroutines
+type Autoscaler struct {
+	Client    civogo.Clienter
+	ClusterID string
+	Signal    Signal
+	Policies  []PoolPolicy
+	// DryRun makes decisions without changing the pools or starting cooldowns
+	DryRun bool
+	// Interval is how often Run checks the pools, defaulting to a minute
+	Interval time.Duration
+	// Now is used for cooldowns, when nil it's time.Now
+	Now func() time.Time
+
+	mu            sync.Mutex
+	lastScaleUp   map[string]time.Time
+	lastScaleDown map[string]time.Time
+}
+
+// New returns an Autoscaler for the cluster's pools
+func New(client civogo.Clienter, clusterID string, signal Signal, policies ...PoolPolicy) *Autoscaler {
+	return &Autoscaler{
+		Client:    client,
+		ClusterID: clusterID,
+		Signal:    signal,
+		Policies:  policies,
+		Interval:  time.Minute,
+	}
+}
+
+// Validate checks the policies, returning every problem at once
+func (a *Autoscaler) Validate() error {
+	problems := []string{}
+	if a.Signal == nil {
+		problems = append(problems, "no signal set")
+	}
+	seen := map[string]bool{}
+	for _, policy := range a.Policies {
+		switch {
+		case policy.PoolID == "":
+			problems = append(problems, "a policy has no pool ID")
+			continue
+		case seen[policy.PoolID]:
+			problems = append(problems, fmt.Sprintf("pool %s has more than one policy", policy.PoolID))
+		}
+		seen[policy.PoolID] = true
+		if policy.Min < 1 {
+			problems = append(problems, fmt.Sprintf("pool %s's minimum must be at least 1", policy.PoolID))
+		}
+		if policy.Max < policy.Min {
+			problems = append(problems, fmt.Sprintf("pool %s's maximum %d is below its minimum %d", policy.PoolID, policy.Max, policy.Min))
+		}
+		if policy.ScaleUpCooldown < 0 || policy.ScaleDownCooldown < 0 {
+			problems = append(problems, fmt.Sprintf("pool %s has a negative cooldown", policy.PoolID))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid autoscaler: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// Run checks the pools every Interval until ctx is done, passing each round's
+// decisions and error to report, which may be nil
+func (a *Autoscaler) Run(ctx context.Context, report func([]Decision, error)) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	interval := a.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		decisions, err := a.Reconcile(ctx)
+		if report != nil {
+			report(decisions, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile checks every pool with a policy once, scaling those which need it.
+// It carries on past pools which fail and returns all the errors joined.
+func (a *Autoscaler) Reconcile(ctx context.Context) ([]Decision, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	pools, err := a.Client.ListKubernetesClusterPools(a.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.lastScaleUp == nil {
+		a.lastScaleUp = map[string]time.Time{}
+		a.lastScaleDown = map[string]time.Time{}
+	}
+
+	decisions := []Decision{}
+	errs := []error{}
+	for _, policy := range a.Policies {
+		pool := findPool(pools, policy.PoolID)
+		if pool == nil {
+			errs = append(errs, fmt.Errorf("pool %s not found in cluster %s", policy.PoolID, a.ClusterID))
+			continue
+		}
+
+		recommendation, err := a.Signal.Recommend(ctx, a.ClusterID, *pool)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pool %s: %w", pool.ID, err))
+			continue
+		}
+		decision := a.decide(policy, *pool, recommendation)
+		if decision.Action != ActionNone && !a.DryRun {
+			if err := a.apply(pool, &decision); err != nil {
+				errs = append(errs, fmt.Errorf("pool %s: %w", pool.ID, err))
+			}
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, errors.Join(errs...)
+}
+
+func (a *Autoscaler) decide(policy PoolPolicy, pool civogo.KubernetesPool, recommendation Recommendation) Decision {
+	current := poolSize(pool)
+	target := recommendation.Nodes
+	reasons := []string{}
+	if recommendation.Reason != "" {
+		reasons = append(reasons, recommendation.Reason)
+	}
+	if target < policy.Min {
+		target = policy.Min
+		reasons = append(reasons, fmt.Sprintf("held at minimum %d", policy.Min))
+	}
+	if target > policy.Max {
+		target = policy.Max
+		reasons = append(reasons, fmt.Sprintf("held at maximum %d", policy.Max))
+	}
+
+	decision := Decision{PoolID: pool.ID, Action: ActionNone, Current: current, Recommended: recommendation.Nodes, Target: target}
+	now := a.now()
+	switch {
+	case target > current:
+		if until := a.lastScaleUp[pool.ID].Add(policy.ScaleUpCooldown); now.Before(until) {
+			reasons = append(reasons, fmt.Sprintf("scale-up cooling down for %s", until.Sub(now)))
+			decision.Target = current
+			break
+		}
+		decision.Action = ActionScaleUp
+	case target < current:
+		last := a.lastScaleDown[pool.ID]
+		if a.lastScaleUp[pool.ID].After(last) {
+			last = a.lastScaleUp[pool.ID]
+		}
+		if until := last.Add(policy.ScaleDownCooldown); now.Before(until) {
+			reasons = append(reasons, fmt.Sprintf("scale-down cooling down for %s", until.Sub(now)))
+			decision.Target = current
+			break
+		}
+		decision.Action = ActionScaleDown
+		decision.RemoveInstances = chooseInstances(pool, current-target, recommendation.RemovalCandidates)
+	}
+	decision.Reason = strings.Join(reasons, ", ")
+	return decision
+}
+
+func (a *Autoscaler) apply(pool *civogo.KubernetesPool, decision *Decision) error {
+	switch decision.Action {
+	case ActionScaleUp:
+		count := decision.Target
+		config := &civogo.KubernetesClusterPoolUpdateConfig{Count: &count, Labels: pool.Labels, Taints: pool.Taints}
+		if _, err := a.Client.UpdateKubernetesClusterPool(a.ClusterID, pool.ID, config); err != nil {
+			return err
+		}
+		a.lastScaleUp[pool.ID] = a.now()
+	case ActionScaleDown:
+		if len(decision.RemoveInstances) < decision.Current-decision.Target {
+			// The pool doesn't list enough instances to choose from, so lower its count
+			count := decision.Target
+			config := &civogo.KubernetesClusterPoolUpdateConfig{Count: &count, Labels: pool.Labels, Taints: pool.Taints}
+			if _, err := a.Client.UpdateKubernetesClusterPool(a.ClusterID, pool.ID, config); err != nil {
+				return err
+			}
+			decision.RemoveInstances = nil
+			a.lastScaleDown[pool.ID] = a.now()
+			break
+		}
+
+		// Removing specific instances, rather than lowering the count, lets the
+		// signal pick which nodes go
+		for i, id := range decision.RemoveInstances {
+			if _, err := a.Client.DeleteKubernetesClusterPoolInstance(a.ClusterID, pool.ID, id); err != nil {
+				decision.RemoveInstances = decision.RemoveInstances[:i]
+				decision.Target = decision.Current - i
+				if i > 0 {
+					decision.Applied = true
+					a.lastScaleDown[pool.ID] = a.now()
+				}
+				return fmt.Errorf("unable to remove instance %s: %w", id, err)
+			}
+		}
+		a.lastScaleDown[pool.ID] = a.now()
+	}
+	decision.Applied = true
+	return nil
+}
+
+func (a *Autoscaler) now() time.Time {
+	if a.Now == nil {
+		return time.Now()
+	}
+	return a.Now()
+}
+
+// chooseInstances picks count instances to remove, taking the candidates which
+// are in the pool first and then the newest instances
+func chooseInstances(pool civogo.KubernetesPool, count int, candidates []string) []string {
+	inPool := map[string]bool{}
+	for _, instance := range pool.Instances {
+		inPool[instance.ID] = true
+	}
+
+	chosen := []string{}
+	picked := map[string]bool{}
+	for _, id := range candidates {
+		if len(chosen) == count {
+			return chosen
+		}
+		if inPool[id] && !picked[id] {
+			chosen = append(chosen, id)
+			picked[id] = true
+		}
+	}
+	for i := len(pool.Instances) - 1; i >= 0 && len(chosen) < count; i-- {
+		if id := pool.Instances[i].ID; !picked[id] {
+			chosen = append(chosen, id)
+			picked[id] = true
+		}
+	}
+	return chosen
+}
+
+// poolSize is the pool's node count, falling back to its instances if Count isn't set
+func poolSize(pool civogo.KubernetesPool) int {
+	if pool.Count == 0 {
+		return len(pool.Instances)
+	}
+	return pool.Count
+}
+
+func findPool(pools []civogo.KubernetesPool, id string) *civogo.KubernetesPool {
+	for i := range pools {
+		if pools[i].ID == id {
+			return &pools[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package autoscaler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/civo/civogo"
+	. "github.com/onsi/gomega"
+)
+
+// staticSignal recommends a fixed size per pool
+type staticSignal map[string]Recommendation
+
+func (s staticSignal) Recommend(_ context.Context, _ string, pool civogo.KubernetesPool) (Recommendation, error) {
+	recommendation, ok := s[pool.ID]
+	if !ok {
+		return Recommendation{}, errors.New("no metrics")
+	}
+	return recommendation, nil
+}
+
+func poolInstanceIDs(client *civogo.FakeClient, clusterID string) []string {
+	pool, _ := client.GetKubernetesClusterPool(clusterID, "workers")
+	ids := []string{}
+	for _, instance := range pool.Instances {
+		ids = append(ids, instance.ID)
+	}
+	return ids
+}
+
+func TestAutoscalerScalesWithinBoundsAndCooldowns(t *testing.T) {
+	g := NewWithT(t)
+
+	signal := staticSignal{"workers": {Nodes: 8, Reason: "pending pods"}}
+	client, _ := civogo.NewFakeClient()
+	cluster, _ := client.NewKubernetesClusters(&civogo.KubernetesClusterConfig{
+		Name:  "production",
+		Pools: []civogo.KubernetesClusterPoolConfig{{ID: "workers", Count: 3, Size: "g4s.kube.medium"}},
+	})
+	clock := civogo.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	autoscaler := New(client, cluster.ID, signal, PoolPolicy{
+		PoolID:            "workers",
+		Min:               2,
+		Max:               5,
+		ScaleUpCooldown:   3 * time.Minute,
+		ScaleDownCooldown: 10 * time.Minute,
+	})
+	autoscaler.Now = clock.Now
+
+	decisions, err := autoscaler.Reconcile(context.Background())
+	g.Expect(err).To(BeNil())
+	g.Expect(decisions).To(Equal([]Decision{{
+		PoolID: "workers", Action: ActionScaleUp, Current: 3, Recommended: 8, Target: 5,
+		Reason: "pending pods, held at maximum 5", Applied: true,
+	}}))
+	g.Expect(poolInstanceIDs(client, autoscaler.ClusterID)).To(HaveLen(5))
+
+	// Scaling down straight after scaling up waits for the cooldown
+	signal["workers"] = Recommendation{Nodes: 1}
+	clock.Advance(time.Minute)
+	decisions, _ = autoscaler.Reconcile(context.Background())
+	g.Expect(decisions[0].Action).To(Equal(ActionNone))
+	g.Expect(decisions[0].Target).To(Equal(5))
+	g.Expect(decisions[0].Reason).To(Equal("held at minimum 2, scale-down cooling down for 9m0s"))
+
+	// The signal's candidates go first, then the newest instances
+	ids := poolInstanceIDs(client, autoscaler.ClusterID)
+	signal["workers"] = Recommendation{Nodes: 2, RemovalCandidates: []string{"missing", ids[0]}}
+	clock.Advance(9 * time.Minute)
+	decisions, err = autoscaler.Reconcile(context.Background())
+	g.Expect(err).To(BeNil())
+	g.Expect(decisions[0].Action).To(Equal(ActionScaleDown))
+	g.Expect(decisions[0].RemoveInstances).To(Equal([]string{ids[0], ids[4], ids[3]}))
+	g.Expect(poolInstanceIDs(client, autoscaler.ClusterID)).To(Equal([]string{ids[1], ids[2]}))
+	pool, _ := client.GetKubernetesClusterPool(autoscaler.ClusterID, "workers")
+	g.Expect(pool.Count).To(Equal(2))
+}
+
+func TestAutoscalerDryRun(t *testing.T) {
+	g := NewWithT(t)
+
+	signal := staticSignal{"workers": {Nodes: 2}}
+	client, _ := civogo.NewFakeClient()
+	cluster, _ := client.NewKubernetesClusters(&civogo.KubernetesClusterConfig{
+		Name:  "production",
+		Pools: []civogo.KubernetesClusterPoolConfig{{ID: "workers", Count: 3, Size: "g4s.kube.medium"}},
+	})
+	autoscaler := &Autoscaler{
+		Client:    client,
+		ClusterID: cluster.ID,
+		Signal:    signal,
+		Policies:  []PoolPolicy{{PoolID: "workers", Min: 2, Max: 5, ScaleUpCooldown: time.Hour}},
+		DryRun:    true,
+	}
+	ids := poolInstanceIDs(client, autoscaler.ClusterID)
+
+	decisions, err := autoscaler.Reconcile(context.Background())
+	g.Expect(err).To(BeNil())
+	g.Expect(decisions[0].Action).To(Equal(ActionScaleDown))
+	g.Expect(decisions[0].RemoveInstances).To(Equal([]string{ids[2]}))
+	g.Expect(decisions[0].Applied).To(BeFalse())
+	g.Expect(poolInstanceIDs(client, autoscaler.ClusterID)).To(Equal(ids))
+
+	// Dry runs don't start cooldowns
+	signal["workers"] = Recommendation{Nodes: 4}
+	decisions, _ = autoscaler.Reconcile(context.Background())
+	g.Expect(decisions[0].Action).To(Equal(ActionScaleUp))
+}
+
+func TestAutoscalerErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := civogo.NewFakeClient()
+	cluster, _ := client.NewKubernetesClusters(&civogo.KubernetesClusterConfig{Name: "production", Pools: []civogo.KubernetesClusterPoolConfig{{ID: "workers", Count: 1}}})
+	autoscaler := &Autoscaler{Client: client, ClusterID: cluster.ID, Signal: staticSignal{}, Policies: []PoolPolicy{{PoolID: "workers", Min: 1, Max: 2}}}
+	_, err := autoscaler.Reconcile(context.Background())
+	g.Expect(err).To(MatchError("pool workers: no metrics"))
+
+	autoscaler.Policies = append(autoscaler.Policies, PoolPolicy{PoolID: "workers", Min: 3, Max: 1}, PoolPolicy{})
+	g.Expect(autoscaler.Validate()).To(MatchError("invalid autoscaler: pool workers has more than one policy, " +
+		"pool workers's maximum 1 is below its minimum 3, a policy has no pool ID"))
+
+	autoscaler.Policies = []PoolPolicy{{PoolID: "gpu", Min: 1, Max: 2}}
+	_, err = autoscaler.Reconcile(context.Background())
+	g.Expect(err).To(MatchError("pool gpu not found in cluster " + autoscaler.ClusterID))
+}
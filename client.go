@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -22,6 +21,8 @@ type Client struct {
 	APIKey           string
 	Region           string
 	LastJSONResponse string
+	// FindMode controls how the Find* methods match, see FindModeExact
+	FindMode FindMode
 
 	httpClient *http.Client
 }
@@ -61,6 +62,8 @@ type ValueAdvanceClientForTesting struct {
 	RequestBody  string
 	URL          string
 	ResponseBody string
+	StatusCode   int
+	Headers      map[string]string
 }
 
 // ResultSuccess represents a successful SimpleResponse
@@ -102,52 +105,35 @@ func NewClient(apiKey, region string) (*Client, error) {
 	return NewClientWithURL(apiKey, "https://api.civo.com", region)
 }
 
-// NewAdvancedClientForTesting initializes a Client connecting to a local test server and allows for specifying methods
+// NewAdvancedClientForTesting initializes a Client connecting to a local test server and allows for specifying methods.
+// Requests must match the method, path and, for PUT, POST and PATCH with a non-empty RequestBody, the JSON body
+// of a configured value; anything else is answered with a 404. Use NewMockServer for ordered expectations and failures reported to a test.
 func NewAdvancedClientForTesting(responses []ConfigAdvanceClientForTesting) (*Client, *httptest.Server, error) {
-	var responseSent bool
-
-	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		body, err := io.ReadAll(req.Body)
-		if err != nil {
-			log.Printf("Error reading body: %v", err)
-			return
-		}
+	mock := NewMockServer(logT{})
+
+	for _, criteria := range responses {
+		for _, value := range criteria.Value {
+			e := mock.Expect(criteria.Method, value.URL)
+			hasBody := criteria.Method == "PUT" || criteria.Method == "POST" || criteria.Method == "PATCH"
+			if hasBody && strings.TrimSpace(value.RequestBody) != "" {
+				e.WithBody(value.RequestBody)
+			}
 
-		req.Body = io.NopCloser(bytes.NewBuffer(body))
-
-		for _, criteria := range responses {
-			// we check the method first
-			if criteria.Method == "PUT" || criteria.Method == "POST" || criteria.Method == "PATCH" {
-				for _, criteria := range criteria.Value {
-					if req.URL.Path == criteria.URL {
-						if strings.TrimSpace(string(body)) == strings.TrimSpace(criteria.RequestBody) {
-							responseSent = true
-							rw.Write([]byte(criteria.ResponseBody))
-						}
-					}
-				}
-			} else {
-				for _, criteria := range criteria.Value {
-					if req.URL.Path == criteria.URL {
-						responseSent = true
-						rw.Write([]byte(criteria.ResponseBody))
-					}
-				}
+			statusCode := value.StatusCode
+			if statusCode == 0 {
+				statusCode = http.StatusOK
 			}
-		}
+			e.RespondWith(statusCode, value.ResponseBody)
 
-		if !responseSent {
-			fmt.Println("Failed to find a matching request!")
-			fmt.Println("Request body:", string(body))
-			fmt.Println("Method:", req.Method)
-			fmt.Println("URL:", req.URL.String())
-			rw.Write([]byte(`{"result": "failed to find a matching request"}`))
+			for key, header := range value.Headers {
+				e.WithResponseHeader(key, header)
+			}
 		}
-	}))
+	}
 
-	client, err := NewClientForTestingWithServer(server)
+	client, err := mock.Client()
 
-	return client, server, err
+	return client, mock.Server(), err
 }
 
 // NewClientForTesting initializes a Client connecting to a local test server
@@ -190,6 +176,15 @@ func (c *Client) prepareClientURL(requestURL string) *url.URL {
 	return u
 }
 
+// clone returns a copy of the client which can send requests concurrently with the original,
+// as sendRequest changes the client's state
+func (c *Client) clone() *Client {
+	clone := *c
+	httpClient := *c.httpClient
+	clone.httpClient = &httpClient
+	return &clone
+}
+
 func (c *Client) sendRequest(req *http.Request) ([]byte, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", c.UserAgent)
@@ -0,0 +1,427 @@
+package civogo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CloudInitMaxSize is the largest user data, once base64 encoded, that an instance accepts
+const CloudInitMaxSize = 65535
+
+const (
+	cloudConfigHeader     = "#cloud-config"
+	cloudInitMIMEBoundary = "==CIVOGO-CLOUD-INIT=="
+)
+
+// CloudInit is the user data passed to an instance in InstanceConfig.Script, made
+// up of an optional cloud-config document and any number of shell scripts. With
+// only one of those it's rendered as is, otherwise as a multi-part MIME message.
+type CloudInit struct {
+	Config  CloudConfig
+	Scripts []CloudInitScript
+}
+
+// CloudInitScript is a shell script run by cloud-init once the instance has booted
+type CloudInitScript struct {
+	Filename string
+	Content  string
+}
+
+// CloudConfig is a cloud-init cloud-config document. Modules without a field of
+// their own are kept in Extra, so parsing and rendering a script doesn't lose them.
+type CloudConfig struct {
+	Users          []CloudConfigUser      `yaml:"users,omitempty"`
+	WriteFiles     []CloudConfigFile      `yaml:"write_files,omitempty"`
+	PackageUpdate  bool                   `yaml:"package_update,omitempty"`
+	PackageUpgrade bool                   `yaml:"package_upgrade,omitempty"`
+	Packages       []string               `yaml:"packages,omitempty"`
+	RunCmd         []CloudConfigCommand   `yaml:"runcmd,omitempty"`
+	Extra          map[string]interface{} `yaml:",inline"`
+}
+
+// CloudConfigUser is an entry in the cloud-config users list, a user called
+// "default" with nothing else set stands for the image's default user
+type CloudConfigUser struct {
+	Name              string             `yaml:"name"`
+	Gecos             string             `yaml:"gecos,omitempty"`
+	Groups            CloudConfigStrings `yaml:"groups,omitempty"`
+	Shell             string             `yaml:"shell,omitempty"`
+	Sudo              string             `yaml:"sudo,omitempty"`
+	LockPasswd        *bool              `yaml:"lock_passwd,omitempty"`
+	SSHAuthorizedKeys []string           `yaml:"ssh_authorized_keys,omitempty"`
+}
+
+// CloudConfigFile is an entry in the cloud-config write_files list
+type CloudConfigFile struct {
+	Path        string `yaml:"path"`
+	Content     string `yaml:"content,omitempty"`
+	Encoding    string `yaml:"encoding,omitempty"`
+	Owner       string `yaml:"owner,omitempty"`
+	Permissions string `yaml:"permissions,omitempty"`
+	Append      bool   `yaml:"append,omitempty"`
+	Defer       bool   `yaml:"defer,omitempty"`
+}
+
+// CloudConfigCommand is an entry in the cloud-config runcmd list, either a
+// command run by the shell or a list of arguments run directly
+type CloudConfigCommand struct {
+	Shell string
+	Args  []string
+}
+
+// CloudConfigStrings is a list of strings which cloud-init also accepts as a
+// single comma separated string, such as a user's groups
+type CloudConfigStrings []string
+
+// NewCloudConfigUser returns a user with the public keys of the given SSH keys,
+// each of which is found by ID or name in ListSSHKeys
+func (c *Client) NewCloudConfigUser(name string, sshKeys ...string) (*CloudConfigUser, error) {
+	keys, err := c.ListSSHKeys()
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	user := &CloudConfigUser{Name: name}
+	for _, search := range sshKeys {
+		key, err := findMatch(keys, search, c.FindMode,
+			func(k SSHKey) string { return k.ID },
+			func(k SSHKey) []string { return []string{k.Name} },
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := user.AddSSHKeys(*key); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// AddSSHKeys authorizes the public keys of the given SSH keys for the user
+func (u *CloudConfigUser) AddSSHKeys(keys ...SSHKey) error {
+	for _, key := range keys {
+		if strings.TrimSpace(key.PublicKey) == "" {
+			err := fmt.Errorf("SSH key %s has no public key", key.Name)
+			return CloudInitInvalidError.wrap(err)
+		}
+		u.SSHAuthorizedKeys = append(u.SSHAuthorizedKeys, strings.TrimSpace(key.PublicKey))
+	}
+	return nil
+}
+
+// AddUser adds a user to the cloud-config
+func (cc *CloudConfig) AddUser(user CloudConfigUser) *CloudConfig {
+	cc.Users = append(cc.Users, user)
+	return cc
+}
+
+// AddFile adds a file to write_files, base64 encoding content which isn't text
+func (cc *CloudConfig) AddFile(filePath, content, permissions string) *CloudConfig {
+	file := CloudConfigFile{Path: filePath, Content: content, Permissions: permissions}
+	if !utf8.ValidString(content) || strings.ContainsRune(content, 0) {
+		file.Content = base64.StdEncoding.EncodeToString([]byte(content))
+		file.Encoding = "b64"
+	}
+	cc.WriteFiles = append(cc.WriteFiles, file)
+	return cc
+}
+
+// AddPackages adds packages to be installed on first boot
+func (cc *CloudConfig) AddPackages(packages ...string) *CloudConfig {
+	cc.Packages = append(cc.Packages, packages...)
+	return cc
+}
+
+// AddCommand adds a command to runcmd, to be run by the shell
+func (cc *CloudConfig) AddCommand(command string) *CloudConfig {
+	cc.RunCmd = append(cc.RunCmd, CloudConfigCommand{Shell: command})
+	return cc
+}
+
+// AddCommandArgs adds a command to runcmd, run directly with the given arguments
+func (cc *CloudConfig) AddCommandArgs(args ...string) *CloudConfig {
+	cc.RunCmd = append(cc.RunCmd, CloudConfigCommand{Args: args})
+	return cc
+}
+
+// IsEmpty returns true if the cloud-config doesn't configure anything
+func (cc *CloudConfig) IsEmpty() bool {
+	return len(cc.Users) == 0 && len(cc.WriteFiles) == 0 && !cc.PackageUpdate && !cc.PackageUpgrade &&
+		len(cc.Packages) == 0 && len(cc.RunCmd) == 0 && len(cc.Extra) == 0
+}
+
+// AddScript adds a shell script, which must start with a #! line
+func (ci *CloudInit) AddScript(filename, content string) *CloudInit {
+	ci.Scripts = append(ci.Scripts, CloudInitScript{Filename: filename, Content: content})
+	return ci
+}
+
+// Validate checks the user data is well formed and fits within CloudInitMaxSize
+func (ci *CloudInit) Validate() error {
+	_, err := ci.Render()
+	return err
+}
+
+// Render returns the user data as a string for InstanceConfig.Script
+func (ci *CloudInit) Render() (string, error) {
+	if err := ci.validateParts(); err != nil {
+		return "", err
+	}
+
+	parts := []cloudInitPart{}
+	if !ci.Config.IsEmpty() {
+		out, err := yaml.Marshal(&ci.Config)
+		if err != nil {
+			return "", CloudInitInvalidError.wrap(err)
+		}
+		parts = append(parts, cloudInitPart{contentType: "text/cloud-config", filename: "cloud-config.yaml", content: cloudConfigHeader + "\n" + string(out)})
+	}
+	for _, script := range ci.Scripts {
+		parts = append(parts, cloudInitPart{contentType: "text/x-shellscript", filename: script.Filename, content: script.Content})
+	}
+
+	var script string
+	switch len(parts) {
+	case 0:
+		return "", nil
+	case 1:
+		script = parts[0].content
+	default:
+		var err error
+		if script, err = renderCloudInitMIME(parts); err != nil {
+			return "", err
+		}
+	}
+
+	if size := base64.StdEncoding.EncodedLen(len(script)); size > CloudInitMaxSize {
+		err := fmt.Errorf("user data is %d bytes once encoded, the limit is %d", size, CloudInitMaxSize)
+		return "", CloudInitTooLargeError.wrap(err)
+	}
+
+	return script, nil
+}
+
+// ApplyTo renders the user data into config.Script
+func (ci *CloudInit) ApplyTo(config *InstanceConfig) error {
+	script, err := ci.Render()
+	if err != nil {
+		return err
+	}
+	config.Script = script
+	return nil
+}
+
+func (ci *CloudInit) validateParts() error {
+	problems := []string{}
+	for i, user := range ci.Config.Users {
+		if user.Name == "" {
+			problems = append(problems, fmt.Sprintf("user %d has no name", i+1))
+		}
+	}
+	for i, file := range ci.Config.WriteFiles {
+		if !path.IsAbs(file.Path) {
+			problems = append(problems, fmt.Sprintf("file %d path %q isn't absolute", i+1, file.Path))
+		}
+	}
+	for i, command := range ci.Config.RunCmd {
+		if command.Shell == "" && len(command.Args) == 0 {
+			problems = append(problems, fmt.Sprintf("command %d is empty", i+1))
+		}
+	}
+	for i, script := range ci.Scripts {
+		if !strings.HasPrefix(script.Content, "#!") {
+			problems = append(problems, fmt.Sprintf("script %d %q doesn't start with #!", i+1, script.Filename))
+		}
+	}
+
+	if len(problems) > 0 {
+		err := fmt.Errorf("%s", strings.Join(problems, ", "))
+		return CloudInitInvalidError.wrap(err)
+	}
+	return nil
+}
+
+type cloudInitPart struct {
+	contentType string
+	filename    string
+	content     string
+}
+
+func renderCloudInitMIME(parts []cloudInitPart) (string, error) {
+	buf := bytes.Buffer{}
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(cloudInitMIMEBoundary); err != nil {
+		return "", CloudInitInvalidError.wrap(err)
+	}
+	for _, part := range parts {
+		if strings.Contains(part.content, cloudInitMIMEBoundary) {
+			err := fmt.Errorf("%s contains the MIME boundary %s", part.filename, cloudInitMIMEBoundary)
+			return "", CloudInitInvalidError.wrap(err)
+		}
+	}
+
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\nMIME-Version: 1.0\r\n\r\n", cloudInitMIMEBoundary)
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType+`; charset="utf-8"`)
+		header.Set("MIME-Version", "1.0")
+		header.Set("Content-Transfer-Encoding", "7bit")
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": part.filename}))
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return "", CloudInitInvalidError.wrap(err)
+		}
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return "", CloudInitInvalidError.wrap(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", CloudInitInvalidError.wrap(err)
+	}
+
+	return buf.String(), nil
+}
+
+// ParseCloudInit parses existing user data, which may be a cloud-config document,
+// a shell script or a multi-part MIME message made up of those
+func ParseCloudInit(script string) (*CloudInit, error) {
+	ci := &CloudInit{}
+	trimmed := strings.TrimLeft(script, " \t\r\n")
+
+	switch {
+	case trimmed == "":
+		return ci, nil
+	case strings.HasPrefix(trimmed, cloudConfigHeader):
+		if err := yaml.Unmarshal([]byte(trimmed), &ci.Config); err != nil {
+			return nil, CloudInitInvalidError.wrap(err)
+		}
+	case strings.HasPrefix(trimmed, "#!"):
+		ci.Scripts = append(ci.Scripts, CloudInitScript{Filename: "script.sh", Content: script})
+	case strings.HasPrefix(trimmed, "Content-Type:") || strings.HasPrefix(trimmed, "MIME-Version:"):
+		if err := ci.parseMIME(trimmed); err != nil {
+			return nil, err
+		}
+	default:
+		err := fmt.Errorf("user data isn't a cloud-config, a shell script or a MIME message")
+		return nil, CloudInitInvalidError.wrap(err)
+	}
+
+	return ci, nil
+}
+
+func (ci *CloudInit) parseMIME(script string) error {
+	msg, err := mail.ReadMessage(strings.NewReader(script))
+	if err != nil {
+		return CloudInitInvalidError.wrap(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		err := fmt.Errorf("expected a multipart message, got %q", msg.Header.Get("Content-Type"))
+		return CloudInitInvalidError.wrap(err)
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	configs := 0
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return CloudInitInvalidError.wrap(err)
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return CloudInitInvalidError.wrap(err)
+		}
+		if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+			if content, err = base64.StdEncoding.DecodeString(string(content)); err != nil {
+				return CloudInitInvalidError.wrap(err)
+			}
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "text/cloud-config":
+			configs++
+			if configs > 1 {
+				err := fmt.Errorf("more than one cloud-config part")
+				return CloudInitInvalidError.wrap(err)
+			}
+			if err := yaml.Unmarshal(content, &ci.Config); err != nil {
+				return CloudInitInvalidError.wrap(err)
+			}
+		case "text/x-shellscript":
+			ci.Scripts = append(ci.Scripts, CloudInitScript{Filename: part.FileName(), Content: string(content)})
+		default:
+			err := fmt.Errorf("unsupported part %s of type %q", part.FileName(), partType)
+			return CloudInitInvalidError.wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalYAML renders a user with only the name "default" as the string "default"
+func (u CloudConfigUser) MarshalYAML() (interface{}, error) {
+	if u.Name == "default" && len(u.Groups) == 0 && u.Sudo == "" && len(u.SSHAuthorizedKeys) == 0 &&
+		u.Gecos == "" && u.Shell == "" && u.LockPasswd == nil {
+		return "default", nil
+	}
+	type plain CloudConfigUser
+	return plain(u), nil
+}
+
+// UnmarshalYAML accepts a user given as a name on its own
+func (u *CloudConfigUser) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	name := ""
+	if err := unmarshal(&name); err == nil {
+		*u = CloudConfigUser{Name: name}
+		return nil
+	}
+	type plain CloudConfigUser
+	return unmarshal((*plain)(u))
+}
+
+// MarshalYAML renders the command as a string or a list of arguments
+func (c CloudConfigCommand) MarshalYAML() (interface{}, error) {
+	if len(c.Args) > 0 {
+		return c.Args, nil
+	}
+	return c.Shell, nil
+}
+
+// UnmarshalYAML accepts a command as either a string or a list of arguments
+func (c *CloudConfigCommand) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&c.Shell); err == nil {
+		return nil
+	}
+	return unmarshal(&c.Args)
+}
+
+// UnmarshalYAML accepts either a list or a comma separated string
+func (s *CloudConfigStrings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	single := ""
+	if err := unmarshal(&single); err == nil {
+		*s = nil
+		for _, item := range strings.Split(single, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*s = append(*s, item)
+			}
+		}
+		return nil
+	}
+	return unmarshal((*[]string)(s))
+}
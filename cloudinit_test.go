@@ -0,0 +1,122 @@
+package civogo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCloudInitRenderCloudConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	ci := &CloudInit{}
+	ci.Config.
+		AddUser(CloudConfigUser{Name: "default"}).
+		AddUser(CloudConfigUser{Name: "deploy", Groups: CloudConfigStrings{"sudo", "docker"}, SSHAuthorizedKeys: []string{"ssh-ed25519 AAAA deploy"}}).
+		AddFile("/etc/motd", "Welcome\n", "0644").
+		AddPackages("nginx", "git").
+		AddCommand("systemctl enable --now nginx").
+		AddCommandArgs("touch", "/tmp/done")
+
+	config := &InstanceConfig{}
+	g.Expect(ci.ApplyTo(config)).To(Succeed())
+	g.Expect(config.Script).To(HavePrefix("#cloud-config\n"))
+	g.Expect(config.Script).To(ContainSubstring("- default\n"))
+	g.Expect(config.Script).To(ContainSubstring("- systemctl enable --now nginx\n"))
+	g.Expect(config.Script).To(ContainSubstring("- - touch\n"))
+
+	parsed, err := ParseCloudInit(config.Script)
+	g.Expect(err).To(BeNil())
+	g.Expect(parsed).To(Equal(ci))
+}
+
+func TestCloudInitRenderMultipart(t *testing.T) {
+	g := NewWithT(t)
+
+	ci := &CloudInit{}
+	ci.Config.AddPackages("htop")
+	ci.AddScript("setup.sh", "#!/bin/bash\necho hello\n")
+
+	script, err := ci.Render()
+	g.Expect(err).To(BeNil())
+	g.Expect(script).To(HavePrefix("Content-Type: multipart/mixed; boundary="))
+	g.Expect(script).To(ContainSubstring("Content-Type: text/x-shellscript"))
+
+	parsed, err := ParseCloudInit(script)
+	g.Expect(err).To(BeNil())
+	g.Expect(parsed).To(Equal(ci))
+
+	// A script on its own is passed through unchanged
+	only := &CloudInit{}
+	only.AddScript("setup.sh", "#!/bin/sh\necho hi\n")
+	script, err = only.Render()
+	g.Expect(err).To(BeNil())
+	g.Expect(script).To(Equal("#!/bin/sh\necho hi\n"))
+}
+
+func TestParseCloudInitExisting(t *testing.T) {
+	g := NewWithT(t)
+
+	parsed, err := ParseCloudInit(`#cloud-config
+timezone: Europe/London
+users:
+  - default
+  - name: admin
+    groups: wheel, adm
+    sudo: ALL=(ALL) NOPASSWD:ALL
+runcmd:
+  - [sh, -c, "echo booted"]
+  - echo done
+`)
+	g.Expect(err).To(BeNil())
+	g.Expect(parsed.Config.Users).To(HaveLen(2))
+	g.Expect(parsed.Config.Users[1].Groups).To(Equal(CloudConfigStrings{"wheel", "adm"}))
+	g.Expect(parsed.Config.RunCmd).To(Equal([]CloudConfigCommand{{Args: []string{"sh", "-c", "echo booted"}}, {Shell: "echo done"}}))
+	g.Expect(parsed.Config.Extra).To(HaveKeyWithValue("timezone", "Europe/London"))
+
+	// Modules without their own field survive rendering
+	script, err := parsed.Render()
+	g.Expect(err).To(BeNil())
+	g.Expect(script).To(ContainSubstring("timezone: Europe/London"))
+
+	_, err = ParseCloudInit("just some text")
+	g.Expect(errors.Is(err, CloudInitInvalidError)).To(BeTrue())
+}
+
+func TestCloudInitValidation(t *testing.T) {
+	g := NewWithT(t)
+
+	ci := &CloudInit{}
+	ci.Config.AddUser(CloudConfigUser{}).AddFile("etc/motd", "hi", "")
+	ci.AddScript("setup.sh", "echo missing shebang")
+	err := ci.Validate()
+	g.Expect(errors.Is(err, CloudInitInvalidError)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("user 1 has no name"))
+	g.Expect(err.Error()).To(ContainSubstring(`file 1 path "etc/motd" isn't absolute`))
+	g.Expect(err.Error()).To(ContainSubstring(`script 1 "setup.sh" doesn't start with #!`))
+
+	large := &CloudInit{}
+	large.AddScript("large.sh", "#!/bin/sh\n# "+strings.Repeat("x", CloudInitMaxSize))
+	err = large.Validate()
+	g.Expect(errors.Is(err, CloudInitTooLargeError)).To(BeTrue())
+}
+
+func TestNewCloudConfigUser(t *testing.T) {
+	g := NewWithT(t)
+
+	client, server, _ := NewClientForTesting(map[string]string{
+		"/v2/sshkeys": `[
+			{"id": "12345", "name": "laptop", "public_key": "ssh-ed25519 AAAA laptop\n"},
+			{"id": "67890", "name": "ci", "public_key": "ssh-rsa BBBB ci"}]`,
+	})
+	defer server.Close()
+
+	user, err := client.NewCloudConfigUser("deploy", "laptop", "67890")
+	g.Expect(err).To(BeNil())
+	g.Expect(user.SSHAuthorizedKeys).To(Equal([]string{"ssh-ed25519 AAAA laptop", "ssh-rsa BBBB ci"}))
+
+	_, err = client.NewCloudConfigUser("deploy", "missing")
+	g.Expect(errors.Is(err, ZeroMatchesError)).To(BeTrue())
+}
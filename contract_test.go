@@ -0,0 +1,254 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// TestFakeClientContract runs the Clienter conformance suite against FakeClient
+func TestFakeClientContract(t *testing.T) {
+	testClienterContract(t, func(t *testing.T) Clienter {
+		client, _ := NewFakeClient()
+		return client
+	})
+}
+
+// TestClientContract runs the Clienter conformance suite against the real Client,
+// talking to a local HTTP server which implements the API on top of a FakeClient
+func TestClientContract(t *testing.T) {
+	testClienterContract(t, func(t *testing.T) Clienter {
+		fake, _ := NewFakeClient()
+		server := newFakeAPIServer(t, fake)
+		client, err := NewClientForTestingWithServer(server)
+		if err != nil {
+			t.Fatalf("unable to create client: %s", err)
+		}
+		return client
+	})
+}
+
+// testClienterContract checks the behaviour every Clienter implementation must share:
+// CRUD round trips, Find* matching rules and the error types returned
+func testClienterContract(t *testing.T, newClient func(t *testing.T) Clienter) {
+	t.Run("Instances", func(t *testing.T) {
+		contractInstances(t, newClient(t))
+	})
+	t.Run("Networks", func(t *testing.T) {
+		contractNetworks(t, newClient(t))
+	})
+	t.Run("KubernetesClusters", func(t *testing.T) {
+		contractKubernetesClusters(t, newClient(t))
+	})
+	t.Run("LoadBalancers", func(t *testing.T) {
+		contractLoadBalancers(t, newClient(t))
+	})
+}
+
+func contractInstances(t *testing.T, c Clienter) {
+	g := NewWithT(t)
+
+	web1, err := c.CreateInstance(&InstanceConfig{Hostname: "web-1.example.com", Tags: []string{"web"}})
+	g.Expect(err).To(BeNil())
+	g.Expect(web1.ID).ToNot(BeEmpty())
+	g.Expect(web1.Hostname).To(Equal("web-1.example.com"))
+
+	web2, err := c.CreateInstance(&InstanceConfig{Hostname: "web-2.example.com"})
+	g.Expect(err).To(BeNil())
+	_, err = c.CreateInstance(&InstanceConfig{Hostname: "db.example.com"})
+	g.Expect(err).To(BeNil())
+
+	all, err := c.ListAllInstances()
+	g.Expect(err).To(BeNil())
+	g.Expect(all).To(HaveLen(3))
+
+	got, err := c.GetInstance(web1.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Hostname).To(Equal("web-1.example.com"))
+	g.Expect(got.Tags).To(Equal([]string{"web"}))
+
+	// An exact hostname or ID wins, a unique partial match is accepted
+	found, err := c.FindInstance("web-2.example.com")
+	g.Expect(err).To(BeNil())
+	g.Expect(found.ID).To(Equal(web2.ID))
+	found, err = c.FindInstance(web1.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(found.ID).To(Equal(web1.ID))
+	found, err = c.FindInstance("db")
+	g.Expect(err).To(BeNil())
+	g.Expect(found.Hostname).To(Equal("db.example.com"))
+
+	_, err = c.FindInstance("web")
+	g.Expect(errors.Is(err, MultipleMatchesError)).To(BeTrue(), "expected MultipleMatchesError, got %v", err)
+	_, err = c.FindInstance("mail")
+	g.Expect(errors.Is(err, ZeroMatchesError)).To(BeTrue(), "expected ZeroMatchesError, got %v", err)
+
+	// Tags are managed as lists, with key=value tags replaced by key
+	_, err = c.AddInstanceTags(web1.ID, []string{"env=prod", "frontend"})
+	g.Expect(err).To(BeNil())
+	_, err = c.AddInstanceTags(web2.ID, []string{"env=staging"})
+	g.Expect(err).To(BeNil())
+	tagged, err := c.ListInstancesByTag("env")
+	g.Expect(err).To(BeNil())
+	g.Expect(tagged).To(HaveLen(2))
+	tagged, err = c.ListInstancesByTag("env=prod")
+	g.Expect(err).To(BeNil())
+	g.Expect(tagged).To(HaveLen(1))
+	g.Expect(tagged[0].ID).To(Equal(web1.ID))
+
+	_, err = c.AddInstanceTags(web1.ID, []string{"env=dev"})
+	g.Expect(err).To(BeNil())
+	_, err = c.RemoveInstanceTags(web1.ID, []string{"frontend"})
+	g.Expect(err).To(BeNil())
+	got, err = c.GetInstance(web1.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Tags).To(Equal([]string{"web", "env=dev"}))
+
+	_, err = c.ReplaceInstanceTags(web2.ID, []string{"bad tag"})
+	g.Expect(errors.Is(err, ParameterTagInvalidError)).To(BeTrue(), "expected ParameterTagInvalidError, got %v", err)
+	_, err = c.AddInstanceTags("missing", []string{"web"})
+	g.Expect(errors.Is(err, DatabaseInstanceNotFoundError)).To(BeTrue(), "expected DatabaseInstanceNotFoundError, got %v", err)
+
+	got.Hostname = "web-1.example.net"
+	_, err = c.UpdateInstance(got)
+	g.Expect(err).To(BeNil())
+	got, err = c.GetInstance(web1.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Hostname).To(Equal("web-1.example.net"))
+
+	resp, err := c.DeleteInstance(web1.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(resp.Result).To(Equal(Result("success")))
+
+	_, err = c.GetInstance(web1.ID)
+	g.Expect(errors.Is(err, DatabaseInstanceNotFoundError)).To(BeTrue(), "expected DatabaseInstanceNotFoundError, got %v", err)
+	_, err = c.DeleteInstance(web1.ID)
+	g.Expect(errors.Is(err, DatabaseInstanceNotFoundError)).To(BeTrue(), "expected DatabaseInstanceNotFoundError, got %v", err)
+}
+
+func contractNetworks(t *testing.T, c Clienter) {
+	g := NewWithT(t)
+
+	frontend, err := c.NewNetwork("frontend")
+	g.Expect(err).To(BeNil())
+	g.Expect(frontend.ID).ToNot(BeEmpty())
+	g.Expect(frontend.Label).To(Equal("frontend"))
+
+	_, err = c.NewNetwork("frontend-staging")
+	g.Expect(err).To(BeNil())
+	_, err = c.NewNetwork("backend")
+	g.Expect(err).To(BeNil())
+
+	networks, err := c.ListNetworks()
+	g.Expect(err).To(BeNil())
+	g.Expect(networks).To(HaveLen(3))
+
+	// "frontend" is both an exact match and part of "frontend-staging"
+	found, err := c.FindNetwork("frontend")
+	g.Expect(err).To(BeNil())
+	g.Expect(found.ID).To(Equal(frontend.ID))
+	found, err = c.FindNetwork("back")
+	g.Expect(err).To(BeNil())
+	g.Expect(found.Label).To(Equal("backend"))
+
+	_, err = c.FindNetwork("end")
+	g.Expect(errors.Is(err, MultipleMatchesError)).To(BeTrue(), "expected MultipleMatchesError, got %v", err)
+	_, err = c.FindNetwork("database")
+	g.Expect(errors.Is(err, ZeroMatchesError)).To(BeTrue(), "expected ZeroMatchesError, got %v", err)
+
+	renamed, err := c.RenameNetwork("web", frontend.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(renamed.Label).To(Equal("web"))
+	found, err = c.FindNetwork("web")
+	g.Expect(err).To(BeNil())
+	g.Expect(found.ID).To(Equal(frontend.ID))
+
+	_, err = c.DeleteNetwork(frontend.ID)
+	g.Expect(err).To(BeNil())
+	_, err = c.DeleteNetwork(frontend.ID)
+	g.Expect(errors.Is(err, DatabaseNetworkNotFoundError)).To(BeTrue(), "expected DatabaseNetworkNotFoundError, got %v", err)
+	_, err = c.RenameNetwork("web", frontend.ID)
+	g.Expect(errors.Is(err, DatabaseNetworkNotFoundError)).To(BeTrue(), "expected DatabaseNetworkNotFoundError, got %v", err)
+}
+
+func contractKubernetesClusters(t *testing.T, c Clienter) {
+	g := NewWithT(t)
+
+	prod, err := c.NewKubernetesClusters(&KubernetesClusterConfig{Name: "prod", NumTargetNodes: 1, TargetNodesSize: "g4s.kube.small"})
+	g.Expect(err).To(BeNil())
+	g.Expect(prod.ID).ToNot(BeEmpty())
+	g.Expect(prod.Name).To(Equal("prod"))
+
+	_, err = c.NewKubernetesClusters(&KubernetesClusterConfig{Name: "prod-eu"})
+	g.Expect(err).To(BeNil())
+	_, err = c.NewKubernetesClusters(&KubernetesClusterConfig{Name: "staging"})
+	g.Expect(err).To(BeNil())
+
+	clusters, err := c.ListKubernetesClusters()
+	g.Expect(err).To(BeNil())
+	g.Expect(clusters.Items).To(HaveLen(3))
+
+	got, err := c.GetKubernetesCluster(prod.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.TargetNodeSize).To(Equal("g4s.kube.small"))
+
+	// Cluster names are matched case-insensitively
+	found, err := c.FindKubernetesCluster("PROD")
+	g.Expect(err).To(BeNil())
+	g.Expect(found.ID).To(Equal(prod.ID))
+	found, err = c.FindKubernetesCluster("Stag")
+	g.Expect(err).To(BeNil())
+	g.Expect(found.Name).To(Equal("staging"))
+
+	_, err = c.FindKubernetesCluster("pro")
+	g.Expect(errors.Is(err, MultipleMatchesError)).To(BeTrue(), "expected MultipleMatchesError, got %v", err)
+	_, err = c.FindKubernetesCluster("dev")
+	g.Expect(errors.Is(err, ZeroMatchesError)).To(BeTrue(), "expected ZeroMatchesError, got %v", err)
+
+	updated, err := c.UpdateKubernetesCluster(prod.ID, &KubernetesClusterConfig{Name: "production"})
+	g.Expect(err).To(BeNil())
+	g.Expect(updated.Name).To(Equal("production"))
+
+	_, err = c.DeleteKubernetesCluster(prod.ID)
+	g.Expect(err).To(BeNil())
+	_, err = c.GetKubernetesCluster(prod.ID)
+	g.Expect(errors.Is(err, DatabaseKubernetesClusterNotFoundError)).To(BeTrue(), "expected DatabaseKubernetesClusterNotFoundError, got %v", err)
+	_, err = c.DeleteKubernetesCluster(prod.ID)
+	g.Expect(errors.Is(err, DatabaseKubernetesClusterNotFoundError)).To(BeTrue(), "expected DatabaseKubernetesClusterNotFoundError, got %v", err)
+}
+
+func contractLoadBalancers(t *testing.T, c Clienter) {
+	g := NewWithT(t)
+
+	lb, err := c.CreateLoadBalancer(&LoadBalancerConfig{Name: "web-lb"})
+	g.Expect(err).To(BeNil())
+	g.Expect(lb.ID).ToNot(BeEmpty())
+	g.Expect(lb.Algorithm).To(Equal("round_robin"))
+
+	_, err = c.CreateLoadBalancer(&LoadBalancerConfig{Name: "web-lb-internal"})
+	g.Expect(err).To(BeNil())
+
+	found, err := c.FindLoadBalancer("web-lb")
+	g.Expect(err).To(BeNil())
+	g.Expect(found.ID).To(Equal(lb.ID))
+	_, err = c.FindLoadBalancer("web")
+	g.Expect(errors.Is(err, MultipleMatchesError)).To(BeTrue(), "expected MultipleMatchesError, got %v", err)
+	_, err = c.FindLoadBalancer("api")
+	g.Expect(errors.Is(err, ZeroMatchesError)).To(BeTrue(), "expected ZeroMatchesError, got %v", err)
+
+	updated, err := c.UpdateLoadBalancer(lb.ID, &LoadBalancerUpdateConfig{Name: "www-lb", Algorithm: "least_connections"})
+	g.Expect(err).To(BeNil())
+	g.Expect(updated.Name).To(Equal("www-lb"))
+	got, err := c.GetLoadBalancer(lb.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Name).To(Equal("www-lb"))
+	g.Expect(got.Algorithm).To(Equal("least_connections"))
+
+	_, err = c.DeleteLoadBalancer(lb.ID)
+	g.Expect(err).To(BeNil())
+	_, err = c.GetLoadBalancer(lb.ID)
+	g.Expect(errors.Is(err, DatabaseLoadBalancerNotFoundError)).To(BeTrue(), "expected DatabaseLoadBalancerNotFoundError, got %v", err)
+	_, err = c.DeleteLoadBalancer(lb.ID)
+	g.Expect(errors.Is(err, DatabaseLoadBalancerNotFoundError)).To(BeTrue(), "expected DatabaseLoadBalancerNotFoundError, got %v", err)
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 )
 
 // DatabaseUserInfo represents the user information
@@ -156,31 +155,10 @@ func (c *Client) FindDatabase(search string) (*Database, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Database{}
-
-	for _, value := range databases.Items {
-		if strings.EqualFold(value.Name, search) || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(strings.ToUpper(value.Name), strings.ToUpper(search)) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(databases.Items, search, c.FindMode,
+		func(d Database) string { return d.ID },
+		func(d Database) []string { return []string{d.Name} },
+	)
 }
 
 // ListDBVersions returns a list of all database versions
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -128,29 +127,8 @@ func (c *Client) FindDatabaseBackup(dbid, search string) (*DatabaseBackup, error
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := DatabaseBackup{}
-
-	for _, value := range backups.Items {
-		if strings.EqualFold(value.Name, search) || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(strings.ToUpper(value.Name), strings.ToUpper(search)) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(backups.Items, search, c.FindMode,
+		func(b DatabaseBackup) string { return b.ID },
+		func(b DatabaseBackup) []string { return []string{b.Name} },
+	)
 }
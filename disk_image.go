@@ -117,31 +117,10 @@ func (c *Client) FindDiskImage(search string) (*DiskImage, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := DiskImage{}
-
-	for _, value := range templateList {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(templateList, search, c.FindMode,
+		func(d DiskImage) string { return d.ID },
+		func(d DiskImage) []string { return []string{d.Name} },
+	)
 }
 
 // GetDiskImageByName finds the DiskImage for an account with the specified code
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -105,31 +104,10 @@ func (c *Client) FindDNSDomain(search string) (*DNSDomain, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := DNSDomain{}
-
-	for _, value := range domains {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(domains, search, c.FindMode,
+		func(d DNSDomain) string { return d.ID },
+		func(d DNSDomain) []string { return []string{d.Name} },
+	)
 }
 
 // CreateDNSDomain registers a new Domain
@@ -50,8 +50,8 @@ func TestFindDNSDomain(t *testing.T) {
 	}
 
 	_, err := client.FindDNSDomain("example")
-	if err.Error() != "MultipleMatchesError: unable to find example because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "MultipleMatchesError: unable to find example because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find example because there were multiple matches: example.com (12345), example.net (12346)" {
+		t.Errorf("Expected %s, got %s", "MultipleMatchesError: unable to find example because there were multiple matches: example.com (12345), example.net (12346)", err.Error())
 	}
 
 	_, err = client.FindDNSDomain("missing")
@@ -21,9 +21,28 @@ var (
 	RegionUnavailableError    = constError("RegionUnavailable")
 
 	CivoStatsdRecordFailedError = constError("CivoStatsdRecordFailedError")
+	CivoStatsdStatsInvalidError = constError("CivoStatsdStatsInvalidError")
 	AuthenticationFailedError   = constError("AuthenticationFailedError")
 	CommonError                 = constError("Error")
 
+	// Instance Error
+	InstancesCreateFailedError            = constError("InstancesCreateFailedError")
+	ParameterHostnameTemplateInvalidError = constError("ParameterHostnameTemplateInvalidError")
+	InstanceInvalidTransitionError        = constError("InstanceInvalidTransitionError")
+	InstanceInvalidSizeError              = constError("InstanceInvalidSizeError")
+	InstanceFailedError                   = constError("InstanceFailedError")
+	ParameterTagInvalidError              = constError("ParameterTagInvalidError")
+	CloudInitInvalidError                 = constError("CloudInitInvalidError")
+	CloudInitTooLargeError                = constError("CloudInitTooLargeError")
+	InstanceVncDurationInvalidError       = constError("InstanceVncDurationInvalidError")
+	ParameterAllowedIPInvalidError        = constError("ParameterAllowedIPInvalidError")
+	ParameterBandwidthInvalidError        = constError("ParameterBandwidthInvalidError")
+	PlacementRuleInvalidError             = constError("PlacementRuleInvalidError")
+	PlacementConflictError                = constError("PlacementConflictError")
+	InstanceProfileInvalidError           = constError("InstanceProfileInvalidError")
+	InstanceProfileNotFoundError          = constError("InstanceProfileNotFoundError")
+	ParameterRecoveryWorkMissingError     = constError("ParameterRecoveryWorkMissingError")
+
 	// Volume Error
 	CannotRescueNewVolumeError              = constError("CannotRescueNewVolumeError")
 	CannotRestoreNewVolumeError             = constError("CannotRestoreNewVolumeError")
@@ -100,6 +119,12 @@ var (
 	DatabaseKubernetesClusterDuplicateError       = constError("DatabaseKubernetesClusterDuplicate")
 	DatabaseKubernetesClusterNotFoundError        = constError("DatabaseKubernetesClusterNotFound")
 	DatabaseKubernetesNodeNotFoundError           = constError("DatabaseKubernetesNodeNotFound")
+	KubeconfigInvalidError                        = constError("KubeconfigInvalidError")
+	KubernetesUpgradeInvalidError                 = constError("KubernetesUpgradeInvalidError")
+	KubernetesClusterFailedError                  = constError("KubernetesClusterFailedError")
+	KubernetesClusterPoolInvalidError             = constError("KubernetesClusterPoolInvalidError")
+	KubernetesApplicationInvalidError             = constError("KubernetesApplicationInvalidError")
+	KubernetesClusterConfigInvalidError           = constError("KubernetesClusterConfigInvalidError")
 
 	DatabaseClusterPoolNotFoundError                       = constError("DatabaseClusterPoolNotFound")
 	DatabaseClusterPoolInstanceNotFoundError               = constError("DatabaseClusterPoolInstanceNotFound")
@@ -0,0 +1,232 @@
+package civogo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAPIErrorCodes maps the errors returned by FakeClient to the HTTP status and
+// error code the Civo API responds with, so decodeError returns the same error
+var fakeAPIErrorCodes = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{DatabaseInstanceNotFoundError, http.StatusNotFound, "database_instance_find"},
+	{DatabaseNetworkNotFoundError, http.StatusNotFound, "database_network_not_found"},
+	{DatabaseKubernetesClusterNotFoundError, http.StatusNotFound, "database_kubernetes_cluster_not_found"},
+	{DatabaseLoadBalancerNotFoundError, http.StatusNotFound, "database_loadbalancer_not_found"},
+}
+
+// newFakeKubernetesCluster returns a FakeClient with a clock, holding the
+// cluster made from config with its nodes also listed as ACTIVE instances
+func newFakeKubernetesCluster(config *KubernetesClusterConfig) (*FakeClient, *KubernetesCluster) {
+	client, _ := NewFakeClient()
+	cluster, _ := client.NewKubernetesClusters(config)
+	for _, node := range cluster.Instances {
+		client.Instances = append(client.Instances, Instance{ID: node.ID, Hostname: node.Hostname, Status: InstanceStatusActive})
+	}
+	client.Clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return client, cluster
+}
+
+// newFakeAPIServer starts a local HTTP server implementing a subset of the Civo
+// API on top of a FakeClient, so the real Client can be tested against the same
+// state as the fake
+func newFakeAPIServer(t *testing.T, fake *FakeClient) *httptest.Server {
+	mux := http.NewServeMux()
+
+	// Instances
+	mux.HandleFunc("GET /v2/instances", func(rw http.ResponseWriter, req *http.Request) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(req.URL.Query().Get("per_page"))
+		writeFakeAPIResponse(rw)(fake.ListInstances(page, perPage))
+	})
+	mux.HandleFunc("POST /v2/instances", func(rw http.ResponseWriter, req *http.Request) {
+		config := &InstanceConfig{}
+		if !decodeFakeAPIRequest(rw, req, config) {
+			return
+		}
+		config.Tags = strings.Fields(config.TagsList)
+		writeFakeAPIResponse(rw)(fake.CreateInstance(config))
+	})
+	mux.HandleFunc("GET /v2/instances/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.GetInstance(req.PathValue("id")))
+	})
+	mux.HandleFunc("PUT /v2/instances/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		instance, err := fake.GetInstance(req.PathValue("id"))
+		if err != nil {
+			writeFakeAPIResponse(rw)(nil, err)
+			return
+		}
+		params := struct {
+			Hostname   string `json:"hostname"`
+			ReverseDNS string `json:"reverse_dns"`
+			Notes      string `json:"notes"`
+		}{}
+		if !decodeFakeAPIRequest(rw, req, &params) {
+			return
+		}
+		instance.Hostname = params.Hostname
+		instance.ReverseDNS = params.ReverseDNS
+		instance.Notes = params.Notes
+		writeFakeAPIResponse(rw)(fake.UpdateInstance(instance))
+	})
+	mux.HandleFunc("PUT /v2/instances/{id}/tags", func(rw http.ResponseWriter, req *http.Request) {
+		params := struct {
+			Tags string `json:"tags"`
+		}{}
+		if !decodeFakeAPIRequest(rw, req, &params) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.ReplaceInstanceTags(req.PathValue("id"), strings.Fields(params.Tags)))
+	})
+	mux.HandleFunc("PUT /v2/instances/{id}/allowed_ips", func(rw http.ResponseWriter, req *http.Request) {
+		params := struct {
+			AllowedIPs []string `json:"allowed_ips"`
+		}{}
+		if !decodeFakeAPIRequest(rw, req, &params) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.UpdateInstanceAllowedIPs(req.PathValue("id"), params.AllowedIPs))
+	})
+	mux.HandleFunc("PUT /v2/instances/{id}/network_bandwidth_limit", func(rw http.ResponseWriter, req *http.Request) {
+		params := struct {
+			NetworkBandwidthLimit int `json:"network_bandwidth_limit"`
+		}{}
+		if !decodeFakeAPIRequest(rw, req, &params) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.UpdateInstanceBandwidth(req.PathValue("id"), params.NetworkBandwidthLimit))
+	})
+	mux.HandleFunc("PUT /v2/instances/{id}/recovery", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.EnableRecoveryMode(req.PathValue("id")))
+	})
+	mux.HandleFunc("GET /v2/instances/{id}/recovery", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.GetRecoveryStatus(req.PathValue("id")))
+	})
+	mux.HandleFunc("DELETE /v2/instances/{id}/recovery", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.DisableRecoveryMode(req.PathValue("id")))
+	})
+	mux.HandleFunc("DELETE /v2/instances/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.DeleteInstance(req.PathValue("id")))
+	})
+
+	// Networks
+	mux.HandleFunc("GET /v2/networks", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.ListNetworks())
+	})
+	mux.HandleFunc("POST /v2/networks", func(rw http.ResponseWriter, req *http.Request) {
+		config := &NetworkConfig{}
+		if !decodeFakeAPIRequest(rw, req, config) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.NewNetwork(config.Label))
+	})
+	mux.HandleFunc("PUT /v2/networks/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		config := &NetworkConfig{}
+		if !decodeFakeAPIRequest(rw, req, config) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.RenameNetwork(config.Label, req.PathValue("id")))
+	})
+	mux.HandleFunc("DELETE /v2/networks/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.DeleteNetwork(req.PathValue("id")))
+	})
+
+	// Kubernetes clusters
+	mux.HandleFunc("GET /v2/kubernetes/clusters", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.ListKubernetesClusters())
+	})
+	mux.HandleFunc("POST /v2/kubernetes/clusters", func(rw http.ResponseWriter, req *http.Request) {
+		config := &KubernetesClusterConfig{}
+		if !decodeFakeAPIRequest(rw, req, config) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.NewKubernetesClusters(config))
+	})
+	mux.HandleFunc("GET /v2/kubernetes/clusters/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.GetKubernetesCluster(req.PathValue("id")))
+	})
+	mux.HandleFunc("PUT /v2/kubernetes/clusters/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		config := &KubernetesClusterConfig{}
+		if !decodeFakeAPIRequest(rw, req, config) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.UpdateKubernetesCluster(req.PathValue("id"), config))
+	})
+	mux.HandleFunc("DELETE /v2/kubernetes/clusters/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.DeleteKubernetesCluster(req.PathValue("id")))
+	})
+
+	// Load balancers
+	mux.HandleFunc("GET /v2/loadbalancers", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.ListLoadBalancers())
+	})
+	mux.HandleFunc("POST /v2/loadbalancers", func(rw http.ResponseWriter, req *http.Request) {
+		config := &LoadBalancerConfig{}
+		if !decodeFakeAPIRequest(rw, req, config) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.CreateLoadBalancer(config))
+	})
+	mux.HandleFunc("GET /v2/loadbalancers/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.GetLoadBalancer(req.PathValue("id")))
+	})
+	mux.HandleFunc("PUT /v2/loadbalancers/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		config := &LoadBalancerUpdateConfig{}
+		if !decodeFakeAPIRequest(rw, req, config) {
+			return
+		}
+		writeFakeAPIResponse(rw)(fake.UpdateLoadBalancer(req.PathValue("id"), config))
+	})
+	mux.HandleFunc("DELETE /v2/loadbalancers/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		writeFakeAPIResponse(rw)(fake.DeleteLoadBalancer(req.PathValue("id")))
+	})
+
+	mux.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
+		t.Errorf("fake API: unhandled request %s %s", req.Method, req.URL)
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(`{"code": "not_found", "reason": "unhandled request"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func decodeFakeAPIRequest(rw http.ResponseWriter, req *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(map[string]string{"code": "parameter_invalid", "reason": err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeFakeAPIResponse returns a function taking the results of a FakeClient call,
+// which writes either the value or the matching API error
+func writeFakeAPIResponse(rw http.ResponseWriter) func(interface{}, error) {
+	return func(value interface{}, err error) {
+		rw.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			for _, mapping := range fakeAPIErrorCodes {
+				if errors.Is(err, mapping.err) {
+					rw.WriteHeader(mapping.status)
+					json.NewEncoder(rw).Encode(map[string]string{"code": mapping.code, "reason": err.Error()})
+					return
+				}
+			}
+			rw.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(rw).Encode(map[string]interface{}{"status": 500, "reason": err.Error()})
+			return
+		}
+		json.NewEncoder(rw).Encode(value)
+	}
+}
@@ -19,6 +19,8 @@ type FakeClient struct {
 	InstanceSizes           []InstanceSize
 	Instances               []Instance
 	Clusters                []KubernetesCluster
+	KubernetesVersions      []KubernetesVersion
+	MarketplaceApplications []KubernetesMarketplaceApplication
 	IP                      []IP
 	Networks                []Network
 	Volumes                 []Volume
@@ -35,8 +37,18 @@ type FakeClient struct {
 	LoadBalancers           []LoadBalancer
 	Pools                   []KubernetesPool
 	PingErr                 error
+	// FindMode controls how the Find* methods match, like Client.FindMode
+	FindMode FindMode
+	// Clock, when set, makes instance and cluster creation and deletion asynchronous, see FakeClock
+	Clock                 *FakeClock
+	InstanceBuildDuration time.Duration
+	ClusterBuildDuration  time.Duration
+	DeletionDuration      time.Duration
+	ActionDuration        time.Duration
 	// Snapshots            []Snapshot
 	// Templates            []Template
+
+	pending []fakeTransition
 }
 
 // Clienter is the interface the real civogo.Client and civogo.FakeClient implement
@@ -75,7 +87,13 @@ type Clienter interface {
 	GetInstance(id string) (*Instance, error)
 	NewInstanceConfig() (*InstanceConfig, error)
 	CreateInstance(config *InstanceConfig) (*Instance, error)
+	CreateInstances(config *InstanceConfig, options *CreateInstancesOptions) ([]Instance, error)
 	SetInstanceTags(i *Instance, tags string) (*SimpleResponse, error)
+	AddInstanceTags(id string, tags []string) (*SimpleResponse, error)
+	RemoveInstanceTags(id string, tags []string) (*SimpleResponse, error)
+	ReplaceInstanceTags(id string, tags []string) (*SimpleResponse, error)
+	ListInstancesByTag(tag string) ([]Instance, error)
+	GetInstanceMetrics(id string) (*InstanceMetrics, error)
 	UpdateInstance(i *Instance) (*SimpleResponse, error)
 	DeleteInstance(id string) (*SimpleResponse, error)
 	RebootInstance(id string) (*SimpleResponse, error)
@@ -86,6 +104,13 @@ type Clienter interface {
 	UpgradeInstance(id, newSize string) (*SimpleResponse, error)
 	MovePublicIPToInstance(id, ipAddress string) (*SimpleResponse, error)
 	SetInstanceFirewall(id, firewallID string) (*SimpleResponse, error)
+	EnableRecoveryMode(id string) (*SimpleResponse, error)
+	DisableRecoveryMode(id string) (*SimpleResponse, error)
+	GetRecoveryStatus(id string) (*SimpleResponse, error)
+	UpdateInstanceAllowedIPs(id string, allowedIPs []string) (*SimpleResponse, error)
+	AddInstanceAllowedIPs(id string, ips []string) (*SimpleResponse, error)
+	RemoveInstanceAllowedIPs(id string, ips []string) (*SimpleResponse, error)
+	UpdateInstanceBandwidth(id string, bandwidthLimit int) (*SimpleResponse, error)
 
 	// Instance sizes
 	ListInstanceSizes() ([]InstanceSize, error)
@@ -108,8 +133,10 @@ type Clienter interface {
 	ListKubernetesClusterPools(cid string) ([]KubernetesPool, error)
 	GetKubernetesClusterPool(cid, pid string) (*KubernetesPool, error)
 	FindKubernetesClusterPool(cid, search string) (*KubernetesPool, error)
+	CreateKubernetesClusterPool(id string, i *KubernetesClusterPoolConfig) (*SimpleResponse, error)
 	DeleteKubernetesClusterPoolInstance(cid, pid, id string) (*SimpleResponse, error)
 	UpdateKubernetesClusterPool(cid, pid string, config *KubernetesClusterPoolUpdateConfig) (*KubernetesPool, error)
+	DeleteKubernetesClusterPool(id, poolID string) (*SimpleResponse, error)
 
 	// Networks
 	GetDefaultNetwork() (*Network, error)
@@ -157,6 +184,8 @@ type Clienter interface {
 
 	// Volumes
 	ListVolumes() ([]Volume, error)
+	ListVolumesForCluster(clusterID string) ([]Volume, error)
+	ListDanglingVolumes() ([]Volume, error)
 	GetVolume(id string) (*Volume, error)
 	FindVolume(search string) (*Volume, error)
 	NewVolume(v *VolumeConfig) (*VolumeResult, error)
@@ -208,6 +237,12 @@ type Clienter interface {
 // NewFakeClient initializes a Client that doesn't attach to a
 func NewFakeClient() (*FakeClient, error) {
 	return &FakeClient{
+		KubernetesVersions: []KubernetesVersion{
+			{
+				Version: "1.20+k3s1",
+				Type:    "stable",
+			},
+		},
 		Quota: Quota{
 			CPUCoreLimit:           10,
 			InstanceCountLimit:     10,
@@ -317,14 +352,10 @@ func (c *FakeClient) ListDNSDomains() ([]DNSDomain, error) {
 
 // FindDNSDomain implemented in a fake way for automated tests
 func (c *FakeClient) FindDNSDomain(search string) (*DNSDomain, error) {
-	for _, domain := range c.Domains {
-		if strings.Contains(domain.Name, search) {
-			return &domain, nil
-		}
-	}
-
-	err := fmt.Errorf("unable to find %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.Domains, search, c.FindMode,
+		func(d DNSDomain) string { return d.ID },
+		func(d DNSDomain) []string { return []string{d.Name} },
+	)
 }
 
 // CreateDNSDomain implemented in a fake way for automated tests
@@ -443,21 +474,21 @@ func (c *FakeClient) ListFirewalls() ([]Firewall, error) {
 
 // FindFirewall implemented in a fake way for automated tests
 func (c *FakeClient) FindFirewall(search string) (*Firewall, error) {
-	for _, firewall := range c.Firewalls {
-		if strings.Contains(firewall.Name, search) {
-			return &firewall, nil
-		}
-	}
-
-	err := fmt.Errorf("unable to find %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.Firewalls, search, c.FindMode,
+		func(f Firewall) string { return f.ID },
+		func(f Firewall) []string { return []string{f.Name} },
+	)
 }
 
 // NewFirewall implemented in a fake way for automated tests
-func (c *FakeClient) NewFirewall(*FirewallConfig) (*FirewallResult, error) {
+func (c *FakeClient) NewFirewall(config *FirewallConfig) (*FirewallResult, error) {
 	firewall := Firewall{
-		ID:   c.generateID(),
-		Name: "fw-name",
+		ID:        c.generateID(),
+		Name:      "fw-name",
+		NetworkID: config.NetworkID,
+	}
+	if config.Name != "" {
+		firewall.Name = config.Name
 	}
 	c.Firewalls = append(c.Firewalls, firewall)
 
@@ -497,12 +528,16 @@ func (c *FakeClient) DeleteFirewall(id string) (*SimpleResponse, error) {
 // NewFirewallRule implemented in a fake way for automated tests
 func (c *FakeClient) NewFirewallRule(r *FirewallRuleConfig) (*FirewallRule, error) {
 	rule := FirewallRule{
-		ID:        c.generateID(),
-		Protocol:  r.Protocol,
-		StartPort: r.StartPort,
-		EndPort:   r.EndPort,
-		Cidr:      r.Cidr,
-		Label:     r.Label,
+		ID:         c.generateID(),
+		FirewallID: r.FirewallID,
+		Protocol:   r.Protocol,
+		StartPort:  r.StartPort,
+		EndPort:    r.EndPort,
+		Cidr:       r.Cidr,
+		Direction:  r.Direction,
+		Action:     r.Action,
+		Label:      r.Label,
+		Ports:      r.Ports,
 	}
 	c.FirewallRules = append(c.FirewallRules, rule)
 	return &rule, nil
@@ -510,19 +545,28 @@ func (c *FakeClient) NewFirewallRule(r *FirewallRuleConfig) (*FirewallRule, erro
 
 // ListFirewallRules implemented in a fake way for automated tests
 func (c *FakeClient) ListFirewallRules(id string) ([]FirewallRule, error) {
-	return c.FirewallRules, nil
+	rules := []FirewallRule{}
+	for _, rule := range c.FirewallRules {
+		if rule.FirewallID == id {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
 }
 
 // FindFirewallRule implemented in a fake way for automated tests
 func (c *FakeClient) FindFirewallRule(firewallID string, search string) (*FirewallRule, error) {
+	rules := []FirewallRule{}
 	for _, rule := range c.FirewallRules {
-		if rule.FirewallID == firewallID && strings.Contains(rule.Label, search) {
-			return &rule, nil
+		if rule.FirewallID == firewallID {
+			rules = append(rules, rule)
 		}
 	}
 
-	err := fmt.Errorf("unable to find %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(rules, search, c.FindMode,
+		func(r FirewallRule) string { return r.ID },
+		func(r FirewallRule) []string { return []string{r.Label} },
+	)
 }
 
 // DeleteFirewallRule implemented in a fake way for automated tests
@@ -540,6 +584,7 @@ func (c *FakeClient) DeleteFirewallRule(id string, ruleID string) (*SimpleRespon
 
 // ListInstances implemented in a fake way for automated tests
 func (c *FakeClient) ListInstances(page int, perPage int) (*PaginatedInstanceList, error) {
+	c.applyTransitions()
 	return &PaginatedInstanceList{
 		Items:   c.Instances,
 		Page:    page,
@@ -550,31 +595,31 @@ func (c *FakeClient) ListInstances(page int, perPage int) (*PaginatedInstanceLis
 
 // ListAllInstances implemented in a fake way for automated tests
 func (c *FakeClient) ListAllInstances() ([]Instance, error) {
+	c.applyTransitions()
 	return c.Instances, nil
 }
 
 // FindInstance implemented in a fake way for automated tests
 func (c *FakeClient) FindInstance(search string) (*Instance, error) {
-	for _, instance := range c.Instances {
-		if strings.Contains(instance.Hostname, search) {
-			return &instance, nil
-		}
-	}
+	c.applyTransitions()
 
-	err := fmt.Errorf("unable to find %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.Instances, search, c.FindMode,
+		func(i Instance) string { return i.ID },
+		func(i Instance) []string { return []string{i.Hostname} },
+	)
 }
 
 // GetInstance implemented in a fake way for automated tests
 func (c *FakeClient) GetInstance(id string) (*Instance, error) {
+	c.applyTransitions()
 	for _, instance := range c.Instances {
 		if instance.ID == id {
 			return &instance, nil
 		}
 	}
 
-	err := fmt.Errorf("unable to find %s, zero matches", id)
-	return nil, ZeroMatchesError.wrap(err)
+	err := fmt.Errorf("unable to find instance %s", id)
+	return nil, DatabaseInstanceNotFoundError.wrap(err)
 }
 
 // NewInstanceConfig implemented in a fake way for automated tests
@@ -594,11 +639,32 @@ func (c *FakeClient) CreateInstance(config *InstanceConfig) (*Instance, error) {
 		SSHKey:      config.SSHKeyID,
 		Tags:        config.Tags,
 		PublicIP:    c.generatePublicIP(),
+		Status:      InstanceStatusActive,
 	}
+
+	id := instance.ID
+	if c.after(c.InstanceBuildDuration, func() { c.setInstanceStatus(id, InstanceStatusActive) }) {
+		instance.Status = InstanceStatusBuilding
+	}
+
 	c.Instances = append(c.Instances, instance)
 	return &instance, nil
 }
 
+// CreateInstances implemented in a fake way for automated tests, the
+// instances are always created one at a time
+func (c *FakeClient) CreateInstances(config *InstanceConfig, options *CreateInstancesOptions) ([]Instance, error) {
+	sequential := CreateInstancesOptions{Concurrency: 1}
+	if options != nil {
+		sequential.Rollback = options.Rollback
+	}
+
+	return createInstances(config, &sequential, c.CreateInstance, func(id string) error {
+		_, err := c.DeleteInstance(id)
+		return err
+	})
+}
+
 // SetInstanceTags implemented in a fake way for automated tests
 func (c *FakeClient) SetInstanceTags(i *Instance, tags string) (*SimpleResponse, error) {
 	for idx, instance := range c.Instances {
@@ -611,6 +677,60 @@ func (c *FakeClient) SetInstanceTags(i *Instance, tags string) (*SimpleResponse,
 	return &SimpleResponse{Result: "failed"}, nil
 }
 
+// ReplaceInstanceTags implemented in a fake way for automated tests
+func (c *FakeClient) ReplaceInstanceTags(id string, tags []string) (*SimpleResponse, error) {
+	if err := validateInstanceTags(tags); err != nil {
+		return nil, err
+	}
+
+	for idx, instance := range c.Instances {
+		if instance.ID == id {
+			c.Instances[idx].Tags = append([]string{}, tags...)
+			return &SimpleResponse{Result: "success"}, nil
+		}
+	}
+
+	err := fmt.Errorf("unable to find instance %s", id)
+	return nil, DatabaseInstanceNotFoundError.wrap(err)
+}
+
+// AddInstanceTags implemented in a fake way for automated tests
+func (c *FakeClient) AddInstanceTags(id string, tags []string) (*SimpleResponse, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ReplaceInstanceTags(id, addInstanceTags(instance.Tags, tags))
+}
+
+// RemoveInstanceTags implemented in a fake way for automated tests
+func (c *FakeClient) RemoveInstanceTags(id string, tags []string) (*SimpleResponse, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ReplaceInstanceTags(id, removeInstanceTags(instance.Tags, tags))
+}
+
+// ListInstancesByTag implemented in a fake way for automated tests
+func (c *FakeClient) ListInstancesByTag(tag string) ([]Instance, error) {
+	c.applyTransitions()
+	return filterInstancesByTag(c.Instances, tag), nil
+}
+
+// GetInstanceMetrics implemented in a fake way for automated tests, decoding
+// the civostatsd fields of the stored instance
+func (c *FakeClient) GetInstanceMetrics(id string) (*InstanceMetrics, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance.Metrics()
+}
+
 // UpdateInstance implemented in a fake way for automated tests
 func (c *FakeClient) UpdateInstance(i *Instance) (*SimpleResponse, error) {
 	for idx, instance := range c.Instances {
@@ -620,44 +740,66 @@ func (c *FakeClient) UpdateInstance(i *Instance) (*SimpleResponse, error) {
 		}
 	}
 
-	return &SimpleResponse{Result: "failed"}, nil
+	err := fmt.Errorf("unable to find instance %s", i.ID)
+	return nil, DatabaseInstanceNotFoundError.wrap(err)
 }
 
 // DeleteInstance implemented in a fake way for automated tests
 func (c *FakeClient) DeleteInstance(id string) (*SimpleResponse, error) {
+	c.applyTransitions()
 	for i, instance := range c.Instances {
 		if instance.ID == id {
+			if c.after(c.DeletionDuration, func() { c.removeInstance(id) }) {
+				c.Instances[i].Status = InstanceStatusDeleting
+				return &SimpleResponse{Result: "success"}, nil
+			}
 			c.Instances[len(c.Instances)-1], c.Instances[i] = c.Instances[i], c.Instances[len(c.Instances)-1]
 			c.Instances = c.Instances[:len(c.Instances)-1]
 			return &SimpleResponse{Result: "success"}, nil
 		}
 	}
 
-	return &SimpleResponse{Result: "failed"}, nil
+	err := fmt.Errorf("unable to find instance %s", id)
+	return nil, DatabaseInstanceNotFoundError.wrap(err)
 }
 
 // RebootInstance implemented in a fake way for automated tests
 func (c *FakeClient) RebootInstance(id string) (*SimpleResponse, error) {
-	return &SimpleResponse{Result: "success"}, nil
+	return c.instancePowerAction(id, InstanceStatusRebooting, InstanceStatusActive)
 }
 
 // HardRebootInstance implemented in a fake way for automated tests
 func (c *FakeClient) HardRebootInstance(id string) (*SimpleResponse, error) {
-	return &SimpleResponse{Result: "success"}, nil
+	return c.instancePowerAction(id, InstanceStatusRebooting, InstanceStatusActive)
 }
 
 // SoftRebootInstance implemented in a fake way for automated tests
 func (c *FakeClient) SoftRebootInstance(id string) (*SimpleResponse, error) {
-	return &SimpleResponse{Result: "success"}, nil
+	return c.instancePowerAction(id, InstanceStatusRebooting, InstanceStatusActive)
 }
 
 // StopInstance implemented in a fake way for automated tests
 func (c *FakeClient) StopInstance(id string) (*SimpleResponse, error) {
-	return &SimpleResponse{Result: "success"}, nil
+	return c.instancePowerAction(id, InstanceStatusStopping, InstanceStatusShutoff)
 }
 
 // StartInstance implemented in a fake way for automated tests
 func (c *FakeClient) StartInstance(id string) (*SimpleResponse, error) {
+	return c.instancePowerAction(id, InstanceStatusStarting, InstanceStatusActive)
+}
+
+// instancePowerAction moves the instance to status, via transitional for
+// FakeClient.ActionDuration when there's a clock
+func (c *FakeClient) instancePowerAction(id, transitional, status string) (*SimpleResponse, error) {
+	if _, err := c.GetInstance(id); err != nil {
+		return nil, err
+	}
+
+	if c.after(c.ActionDuration, func() { c.setInstanceStatus(id, status) }) {
+		c.setInstanceStatus(id, transitional)
+	} else {
+		c.setInstanceStatus(id, status)
+	}
 	return &SimpleResponse{Result: "success"}, nil
 }
 
@@ -668,14 +810,26 @@ func (c *FakeClient) GetInstanceConsoleURL(id string) (string, error) {
 
 // UpgradeInstance implemented in a fake way for automated tests
 func (c *FakeClient) UpgradeInstance(id, newSize string) (*SimpleResponse, error) {
-	for idx, instance := range c.Instances {
-		if instance.ID == id {
-			c.Instances[idx].Size = newSize
-			return &SimpleResponse{Result: "success"}, nil
-		}
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, err
 	}
 
-	return &SimpleResponse{Result: "failed"}, nil
+	status := instance.Status
+	resize := func() {
+		for idx, instance := range c.Instances {
+			if instance.ID == id {
+				c.Instances[idx].Size = newSize
+				c.Instances[idx].Status = status
+			}
+		}
+	}
+	if c.after(c.ActionDuration, resize) {
+		c.setInstanceStatus(id, InstanceStatusResizing)
+	} else {
+		resize()
+	}
+	return &SimpleResponse{Result: "success"}, nil
 }
 
 // MovePublicIPToInstance implemented in a fake way for automated tests
@@ -704,6 +858,109 @@ func (c *FakeClient) MovePublicIPToInstance(id, ipAddress string) (*SimpleRespon
 	return &SimpleResponse{Result: "success"}, nil
 }
 
+// EnableRecoveryMode implemented in a fake way for automated tests, when there's
+// a clock the instance is REBOOTING for FakeClient.ActionDuration before its
+// rescue password is set
+func (c *FakeClient) EnableRecoveryMode(id string) (*SimpleResponse, error) {
+	if _, err := c.GetInstance(id); err != nil {
+		return nil, err
+	}
+
+	rescue := func() {
+		for idx, instance := range c.Instances {
+			if instance.ID == id {
+				c.Instances[idx].Status = InstanceStatusActive
+				c.Instances[idx].RescuePassword = "rescue-" + id
+			}
+		}
+	}
+	if c.after(c.ActionDuration, rescue) {
+		c.setInstanceStatus(id, InstanceStatusRebooting)
+	} else {
+		rescue()
+	}
+	return &SimpleResponse{Result: "success"}, nil
+}
+
+// DisableRecoveryMode implemented in a fake way for automated tests
+func (c *FakeClient) DisableRecoveryMode(id string) (*SimpleResponse, error) {
+	if _, err := c.GetInstance(id); err != nil {
+		return nil, err
+	}
+
+	for idx, instance := range c.Instances {
+		if instance.ID == id {
+			c.Instances[idx].RescuePassword = ""
+		}
+	}
+	return c.instancePowerAction(id, InstanceStatusRebooting, InstanceStatusActive)
+}
+
+// GetRecoveryStatus implemented in a fake way for automated tests
+func (c *FakeClient) GetRecoveryStatus(id string) (*SimpleResponse, error) {
+	if _, err := c.GetInstance(id); err != nil {
+		return nil, err
+	}
+	return &SimpleResponse{ID: id, Result: ResultSuccess}, nil
+}
+
+// UpdateInstanceAllowedIPs implemented in a fake way for automated tests
+func (c *FakeClient) UpdateInstanceAllowedIPs(id string, allowedIPs []string) (*SimpleResponse, error) {
+	allowedIPs, err := NormalizeAllowedIPs(allowedIPs)
+	if err != nil {
+		return nil, err
+	}
+	for idx, instance := range c.Instances {
+		if instance.ID == id {
+			c.Instances[idx].AllowedIPs = allowedIPs
+			return &SimpleResponse{Result: "success"}, nil
+		}
+	}
+
+	err = fmt.Errorf("unable to find instance %s", id)
+	return nil, DatabaseInstanceNotFoundError.wrap(err)
+}
+
+// AddInstanceAllowedIPs implemented in a fake way for automated tests
+func (c *FakeClient) AddInstanceAllowedIPs(id string, ips []string) (*SimpleResponse, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateInstanceAllowedIPs(c, instance, func(current []string) ([]string, error) {
+		return addAllowedIPs(current, ips)
+	})
+}
+
+// RemoveInstanceAllowedIPs implemented in a fake way for automated tests
+func (c *FakeClient) RemoveInstanceAllowedIPs(id string, ips []string) (*SimpleResponse, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateInstanceAllowedIPs(c, instance, func(current []string) ([]string, error) {
+		return removeAllowedIPs(current, ips)
+	})
+}
+
+// UpdateInstanceBandwidth implemented in a fake way for automated tests
+func (c *FakeClient) UpdateInstanceBandwidth(id string, bandwidthLimit int) (*SimpleResponse, error) {
+	if err := validateBandwidthLimit(bandwidthLimit); err != nil {
+		return nil, err
+	}
+	for idx, instance := range c.Instances {
+		if instance.ID == id {
+			c.Instances[idx].NetworkBandwidthLimit = bandwidthLimit
+			return &SimpleResponse{Result: "success"}, nil
+		}
+	}
+
+	err := fmt.Errorf("unable to find instance %s", id)
+	return nil, DatabaseInstanceNotFoundError.wrap(err)
+}
+
 // SetInstanceFirewall implemented in a fake way for automated tests
 func (c *FakeClient) SetInstanceFirewall(id, firewallID string) (*SimpleResponse, error) {
 	for idx, instance := range c.Instances {
@@ -723,18 +980,12 @@ func (c *FakeClient) ListInstanceSizes() ([]InstanceSize, error) {
 
 // FindInstanceSizes implemented in a fake way for automated tests
 func (c *FakeClient) FindInstanceSizes(search string) (*InstanceSize, error) {
-	for _, size := range c.InstanceSizes {
-		if strings.Contains(size.Name, search) {
-			return &size, nil
-		}
-	}
-
-	err := fmt.Errorf("unable to find %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.InstanceSizes, search, c.FindMode, func(s InstanceSize) string { return s.Name }, func(s InstanceSize) []string { return nil })
 }
 
 // ListKubernetesClusters implemented in a fake way for automated tests
 func (c *FakeClient) ListKubernetesClusters() (*PaginatedKubernetesClusters, error) {
+	c.applyTransitions()
 	return &PaginatedKubernetesClusters{
 		Items:   c.Clusters,
 		Page:    1,
@@ -745,18 +996,17 @@ func (c *FakeClient) ListKubernetesClusters() (*PaginatedKubernetesClusters, err
 
 // FindKubernetesCluster implemented in a fake way for automated tests
 func (c *FakeClient) FindKubernetesCluster(search string) (*KubernetesCluster, error) {
-	for _, cluster := range c.Clusters {
-		if strings.Contains(cluster.Name, search) || cluster.ID == search {
-			return &cluster, nil
-		}
-	}
+	c.applyTransitions()
 
-	err := fmt.Errorf("unable to find %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.Clusters, search, c.FindMode,
+		func(k KubernetesCluster) string { return k.ID },
+		func(k KubernetesCluster) []string { return []string{k.Name} },
+	)
 }
 
 // ListKubernetesClusterInstances implemented in a fake way for automated tests
 func (c *FakeClient) ListKubernetesClusterInstances(id string) ([]Instance, error) {
+	c.applyTransitions()
 	for _, cluster := range c.Clusters {
 		if cluster.ID == id {
 			instaces := make([]Instance, 0)
@@ -782,121 +1032,212 @@ func (c *FakeClient) FindKubernetesClusterInstance(clusterID, search string) (*I
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Instance{}
-
-	for _, instance := range instances {
-		if instance.Hostname == search || instance.ID == search {
-			exactMatch = true
-			result = instance
-		} else if strings.Contains(instance.Hostname, search) || strings.Contains(instance.ID, search) {
-			if !exactMatch {
-				result = instance
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(instances, search, c.FindMode,
+		func(i Instance) string { return i.ID },
+		func(i Instance) []string { return []string{i.Hostname} },
+	)
 }
 
 // NewKubernetesClusters implemented in a fake way for automated tests
 func (c *FakeClient) NewKubernetesClusters(kc *KubernetesClusterConfig) (*KubernetesCluster, error) {
 	cluster := KubernetesCluster{
-		ID:             c.generateID(),
-		Name:           kc.Name,
-		MasterIP:       c.generatePublicIP(),
-		NumTargetNode:  kc.NumTargetNodes,
-		TargetNodeSize: kc.TargetNodesSize,
-		Ready:          true,
-		Status:         "ACTIVE",
-		Instances:      make([]KubernetesInstance, 0),
-		Pools:          make([]KubernetesPool, 0),
-	}
-	pool := KubernetesPool{
-		Instances: make([]KubernetesInstance, 0),
-	}
-	for i := 0; i < kc.NumTargetNodes; i++ {
-		instance := KubernetesInstance{
-			ID:       c.generateID(),
-			Hostname: fmt.Sprintf("%s_pool_%d", kc.Name, i),
+		ID:                c.generateID(),
+		Name:              kc.Name,
+		MasterIP:          c.generatePublicIP(),
+		NumTargetNode:     kc.NumTargetNodes,
+		TargetNodeSize:    kc.TargetNodesSize,
+		ClusterType:       kc.ClusterType,
+		KubernetesVersion: kc.KubernetesVersion,
+		NetworkID:         kc.NetworkID,
+		FirewallID:        kc.FirewallID,
+		CNIPlugin:         kc.CNIPlugin,
+		Tags:              strings.Fields(kc.Tags),
+		Ready:             true,
+		Status:            "ACTIVE",
+		Instances:         make([]KubernetesInstance, 0),
+		Pools:             make([]KubernetesPool, 0),
+	}
+	if len(kc.Pools) == 0 {
+		pool := KubernetesPool{
+			Instances: make([]KubernetesInstance, 0),
+		}
+		for i := 0; i < kc.NumTargetNodes; i++ {
+			instance := KubernetesInstance{
+				ID:       c.generateID(),
+				Hostname: fmt.Sprintf("%s_pool_%d", kc.Name, i),
+			}
+			pool.Instances = append(pool.Instances, instance)
+			pool.InstanceNames = append(pool.InstanceNames, instance.Hostname)
+			cluster.Instances = append(cluster.Instances, instance)
 		}
-		pool.Instances = append(pool.Instances, instance)
-		cluster.Instances = append(pool.Instances, instance)
+		pool.ID = c.generateID()
+		pool.Count = kc.NumTargetNodes
+		pool.Size = kc.TargetNodesSize
+		cluster.Pools = append(cluster.Pools, pool)
 	}
-
-	cluster.Pools = append(cluster.Pools, pool)
 	c.Clusters = append(c.Clusters, cluster)
-	return &cluster, nil
+
+	id := cluster.ID
+	for _, pool := range kc.Pools {
+		if _, err := c.CreateKubernetesClusterPool(id, &pool); err != nil {
+			c.removeCluster(id)
+			return nil, err
+		}
+	}
+	if kc.Applications != "" {
+		c.updateFakeApplications(len(c.Clusters)-1, kc.Applications)
+	}
+	c.setClusterReady(id, c.Clock == nil)
+	c.after(c.ClusterBuildDuration, func() { c.setClusterReady(id, true) })
+
+	created := c.Clusters[len(c.Clusters)-1]
+	return &created, nil
 }
 
 // GetKubernetesCluster implemented in a fake way for automated tests
 func (c *FakeClient) GetKubernetesCluster(id string) (*KubernetesCluster, error) {
+	c.applyTransitions()
 	for _, cluster := range c.Clusters {
 		if cluster.ID == id {
 			return &cluster, nil
 		}
 	}
 
-	err := fmt.Errorf("unable to find %s, zero matches", id)
-	return nil, ZeroMatchesError.wrap(err)
+	err := fmt.Errorf("unable to find kubernetes cluster %s", id)
+	return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
 }
 
 // UpdateKubernetesCluster implemented in a fake way for automated tests
 func (c *FakeClient) UpdateKubernetesCluster(id string, kc *KubernetesClusterConfig) (*KubernetesCluster, error) {
+	c.applyTransitions()
 	for i, cluster := range c.Clusters {
 		if cluster.ID == id {
-			c.Clusters[i].Name = kc.Name
-			c.Clusters[i].NumTargetNode = kc.NumTargetNodes
-			c.Clusters[i].TargetNodeSize = kc.TargetNodesSize
-			return &cluster, nil
+			if kc.Name != "" {
+				c.Clusters[i].Name = kc.Name
+			}
+			if kc.NumTargetNodes != 0 {
+				c.Clusters[i].NumTargetNode = kc.NumTargetNodes
+			}
+			if kc.TargetNodesSize != "" {
+				c.Clusters[i].TargetNodeSize = kc.TargetNodesSize
+			}
+			if kc.KubernetesVersion != "" && kc.KubernetesVersion != cluster.KubernetesVersion {
+				c.upgradeCluster(id, kc.KubernetesVersion)
+			}
+			if kc.Applications != "" {
+				c.updateFakeApplications(i, kc.Applications)
+			}
+			updated := c.Clusters[i]
+			return &updated, nil
 		}
 	}
 
-	err := fmt.Errorf("unable to find %s, zero matches", id)
-	return nil, ZeroMatchesError.wrap(err)
+	err := fmt.Errorf("unable to find kubernetes cluster %s", id)
+	return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
 }
 
 // ListKubernetesMarketplaceApplications implemented in a fake way for automated tests
 func (c *FakeClient) ListKubernetesMarketplaceApplications() ([]KubernetesMarketplaceApplication, error) {
-	return []KubernetesMarketplaceApplication{}, nil
+	if c.MarketplaceApplications == nil {
+		return []KubernetesMarketplaceApplication{}, nil
+	}
+	return c.MarketplaceApplications, nil
+}
+
+// updateFakeApplications installs the "name:plan" entries in applications on the
+// cluster and uninstalls the "-name" ones
+func (c *FakeClient) updateFakeApplications(ci int, applications string) {
+	for _, entry := range strings.Split(applications, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		installed := c.Clusters[ci].InstalledApplications
+		if name, ok := strings.CutPrefix(entry, "-"); ok {
+			for i, application := range installed {
+				if strings.EqualFold(application.Name, name) {
+					c.Clusters[ci].InstalledApplications = append(installed[:i], installed[i+1:]...)
+					break
+				}
+			}
+			continue
+		}
+
+		name, plan, _ := strings.Cut(entry, ":")
+		application := KubernetesInstalledApplication{Application: name, Name: name, Installed: true, Plan: plan, Configuration: map[string]ApplicationConfiguration{}}
+		for _, app := range c.MarketplaceApplications {
+			if !strings.EqualFold(app.Name, name) {
+				continue
+			}
+			application.Version = app.Version
+			application.Dependencies = app.Dependencies
+			for _, p := range app.Plans {
+				if p.Label == plan {
+					for key, value := range p.Configuration {
+						application.Configuration[key] = ApplicationConfiguration{"value": value.Value}
+					}
+				}
+			}
+		}
+
+		replaced := false
+		for i, existing := range installed {
+			if strings.EqualFold(existing.Name, name) {
+				installed[i] = application
+				replaced = true
+			}
+		}
+		if !replaced {
+			c.Clusters[ci].InstalledApplications = append(installed, application)
+		}
+	}
 }
 
 // DeleteKubernetesCluster implemented in a fake way for automated tests
 func (c *FakeClient) DeleteKubernetesCluster(id string) (*SimpleResponse, error) {
+	c.applyTransitions()
 	for i, cluster := range c.Clusters {
 		if cluster.ID == id {
+			if c.after(c.DeletionDuration, func() { c.removeCluster(id) }) {
+				c.setClusterStatus(id, "DELETING")
+				return &SimpleResponse{Result: "success"}, nil
+			}
 			c.Clusters[len(c.Clusters)-1], c.Clusters[i] = c.Clusters[i], c.Clusters[len(c.Clusters)-1]
 			c.Clusters = c.Clusters[:len(c.Clusters)-1]
 			return &SimpleResponse{Result: "success"}, nil
 		}
 	}
 
-	return &SimpleResponse{Result: "failed"}, nil
+	err := fmt.Errorf("unable to find kubernetes cluster %s", id)
+	return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
 }
 
 // RecycleKubernetesCluster implemented in a fake way for automated tests
 func (c *FakeClient) RecycleKubernetesCluster(id string, hostname string) (*SimpleResponse, error) {
-	return &SimpleResponse{Result: "success"}, nil
+	c.applyTransitions()
+	for _, cluster := range c.Clusters {
+		if cluster.ID != id {
+			continue
+		}
+		for _, node := range cluster.Instances {
+			if node.Hostname == hostname {
+				c.recycleNode(id, node.ID)
+				return &SimpleResponse{Result: "success"}, nil
+			}
+		}
+
+		err := fmt.Errorf("unable to find node %s in kubernetes cluster %s", hostname, id)
+		return nil, DatabaseKubernetesNodeNotFoundError.wrap(err)
+	}
+
+	err := fmt.Errorf("unable to find kubernetes cluster %s", id)
+	return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
 }
 
 // ListAvailableKubernetesVersions implemented in a fake way for automated tests
 func (c *FakeClient) ListAvailableKubernetesVersions() ([]KubernetesVersion, error) {
-	return []KubernetesVersion{
-		{
-			Version: "1.20+k3s1",
-			Type:    "stable",
-		},
-	}, nil
+	return c.KubernetesVersions, nil
 }
 
 // GetDefaultNetwork implemented in a fake way for automated tests
@@ -914,8 +1255,9 @@ func (c *FakeClient) GetDefaultNetwork() (*Network, error) {
 // NewNetwork implemented in a fake way for automated tests
 func (c *FakeClient) NewNetwork(label string) (*NetworkResult, error) {
 	network := Network{
-		ID:   c.generateID(),
-		Name: label,
+		ID:    c.generateID(),
+		Name:  label,
+		Label: label,
 	}
 	c.Networks = append(c.Networks, network)
 
@@ -968,31 +1310,28 @@ func (c *FakeClient) ListNetworks() ([]Network, error) {
 
 // FindNetwork implemented in a fake way for automated tests
 func (c *FakeClient) FindNetwork(search string) (*Network, error) {
-	for _, network := range c.Networks {
-		if strings.Contains(network.Name, search) {
-			return &network, nil
-		}
-	}
-
-	err := fmt.Errorf("unable to find default network, zero matches")
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.Networks, search, c.FindMode,
+		func(n Network) string { return n.ID },
+		func(n Network) []string { return []string{n.Label, n.Name} },
+	)
 }
 
 // RenameNetwork implemented in a fake way for automated tests
 func (c *FakeClient) RenameNetwork(label, id string) (*NetworkResult, error) {
 	for i, network := range c.Networks {
 		if network.ID == id {
+			c.Networks[i].Name = label
 			c.Networks[i].Label = label
 			return &NetworkResult{
 				ID:     network.ID,
-				Label:  network.Label,
+				Label:  label,
 				Result: "success",
 			}, nil
 		}
 	}
 
-	err := fmt.Errorf("unable to find default network, zero matches")
-	return nil, ZeroMatchesError.wrap(err)
+	err := fmt.Errorf("unable to find network %s", id)
+	return nil, DatabaseNetworkNotFoundError.wrap(err)
 }
 
 // DeleteNetwork implemented in a fake way for automated tests
@@ -1005,7 +1344,8 @@ func (c *FakeClient) DeleteNetwork(id string) (*SimpleResponse, error) {
 		}
 	}
 
-	return &SimpleResponse{Result: "failed"}, nil
+	err := fmt.Errorf("unable to find network %s", id)
+	return nil, DatabaseNetworkNotFoundError.wrap(err)
 }
 
 // GetQuota implemented in a fake way for automated tests
@@ -1118,14 +1458,10 @@ func (c *FakeClient) UpdateSSHKey(name string, sshKeyID string) (*SSHKey, error)
 
 // FindSSHKey implemented in a fake way for automated tests
 func (c *FakeClient) FindSSHKey(search string) (*SSHKey, error) {
-	for _, sshKey := range c.SSHKeys {
-		if strings.Contains(sshKey.Name, search) {
-			return &sshKey, nil
-		}
-	}
-
-	err := fmt.Errorf("unable to find SSH key %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.SSHKeys, search, c.FindMode,
+		func(k SSHKey) string { return k.ID },
+		func(k SSHKey) []string { return []string{k.Name} },
+	)
 }
 
 // DeleteSSHKey implemented in a fake way for automated tests
@@ -1229,14 +1565,10 @@ func (c *FakeClient) GetDiskImage(id string) (*DiskImage, error) {
 
 // FindDiskImage implemented in a fake way for automated tests
 func (c *FakeClient) FindDiskImage(search string) (*DiskImage, error) {
-	for _, diskimage := range c.DiskImage {
-		if strings.Contains(diskimage.Name, search) || strings.Contains(diskimage.ID, search) {
-			return &diskimage, nil
-		}
-	}
-
-	err := fmt.Errorf("unable to find volume %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.DiskImage, search, c.FindMode,
+		func(d DiskImage) string { return d.ID },
+		func(d DiskImage) []string { return []string{d.Name} },
+	)
 }
 
 // ListVolumes implemented in a fake way for automated tests
@@ -1244,6 +1576,39 @@ func (c *FakeClient) ListVolumes() ([]Volume, error) {
 	return c.Volumes, nil
 }
 
+// ListVolumesForCluster implemented in a fake way for automated tests
+func (c *FakeClient) ListVolumesForCluster(clusterID string) ([]Volume, error) {
+	cluster, err := c.FindKubernetesCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []Volume
+	for _, volume := range c.Volumes {
+		if volume.ClusterID != "" && volume.ClusterID == cluster.ID {
+			volumes = append(volumes, volume)
+		}
+	}
+	return volumes, nil
+}
+
+// ListDanglingVolumes implemented in a fake way for automated tests
+func (c *FakeClient) ListDanglingVolumes() ([]Volume, error) {
+	c.applyTransitions()
+	clusterIDs := []string{}
+	for _, cluster := range c.Clusters {
+		clusterIDs = append(clusterIDs, cluster.ID)
+	}
+
+	volumes := []Volume{}
+	for _, volume := range c.Volumes {
+		if volume.ClusterID != "" && !findString(clusterIDs, volume.ClusterID) {
+			volumes = append(volumes, volume)
+		}
+	}
+	return volumes, nil
+}
+
 // GetVolume implemented in a fake way for automated tests
 func (c *FakeClient) GetVolume(id string) (*Volume, error) {
 	for _, volume := range c.Volumes {
@@ -1258,14 +1623,10 @@ func (c *FakeClient) GetVolume(id string) (*Volume, error) {
 
 // FindVolume implemented in a fake way for automated tests
 func (c *FakeClient) FindVolume(search string) (*Volume, error) {
-	for _, volume := range c.Volumes {
-		if strings.Contains(volume.Name, search) || strings.Contains(volume.ID, search) {
-			return &volume, nil
-		}
-	}
-
-	err := fmt.Errorf("unable to find volume %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.Volumes, search, c.FindMode,
+		func(v Volume) string { return v.ID },
+		func(v Volume) []string { return []string{v.Name} },
+	)
 }
 
 // NewVolume implemented in a fake way for automated tests
@@ -1446,14 +1807,10 @@ func (c *FakeClient) ListWebhooks() ([]Webhook, error) {
 
 // FindWebhook implemented in a fake way for automated tests
 func (c *FakeClient) FindWebhook(search string) (*Webhook, error) {
-	for _, webhook := range c.Webhooks {
-		if strings.Contains(webhook.Secret, search) || strings.Contains(webhook.URL, search) {
-			return &webhook, nil
-		}
-	}
-
-	err := fmt.Errorf("unable to find %s, zero matches", search)
-	return nil, ZeroMatchesError.wrap(err)
+	return findMatch(c.Webhooks, search, c.FindMode,
+		func(w Webhook) string { return w.ID },
+		func(w Webhook) []string { return []string{w.URL} },
+	)
 }
 
 // UpdateWebhook implemented in a fake way for automated tests
@@ -1668,31 +2025,10 @@ func (c *FakeClient) GetLoadBalancer(id string) (*LoadBalancer, error) {
 
 // FindLoadBalancer implemented in a fake way for automated tests
 func (c *FakeClient) FindLoadBalancer(search string) (*LoadBalancer, error) {
-	exactMatch := false
-	partialMatchesCount := 0
-	result := LoadBalancer{}
-
-	for _, lb := range c.LoadBalancers {
-		if lb.ID == search || lb.Name == search {
-			exactMatch = true
-			result = lb
-		} else if strings.Contains(lb.Name, search) || strings.Contains(lb.ID, search) {
-			if !exactMatch {
-				result = lb
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(c.LoadBalancers, search, c.FindMode,
+		func(l LoadBalancer) string { return l.ID },
+		func(l LoadBalancer) []string { return []string{l.Name} },
+	)
 }
 
 // CreateLoadBalancer implemented in a fake way for automated tests
@@ -1739,7 +2075,7 @@ func (c *FakeClient) CreateLoadBalancer(r *LoadBalancerConfig) (*LoadBalancer, e
 
 // UpdateLoadBalancer implemented in a fake way for automated tests
 func (c *FakeClient) UpdateLoadBalancer(id string, r *LoadBalancerUpdateConfig) (*LoadBalancer, error) {
-	for _, lb := range c.LoadBalancers {
+	for i, lb := range c.LoadBalancers {
 		if lb.ID == id {
 			lb.Name = r.Name
 			lb.Algorithm = r.Algorithm
@@ -1756,10 +2092,16 @@ func (c *FakeClient) UpdateLoadBalancer(id string, r *LoadBalancerUpdateConfig)
 				backends[i].TargetPort = b.TargetPort
 			}
 
+			lb.Backends = backends
+
+			if r.Algorithm == "" {
+				lb.Algorithm = "round_robin"
+			}
 			if r.ExternalTrafficPolicy == "" {
 				lb.ExternalTrafficPolicy = "Cluster"
 			}
 
+			c.LoadBalancers[i] = lb
 			return &lb, nil
 		}
 	}
@@ -1778,11 +2120,13 @@ func (c *FakeClient) DeleteLoadBalancer(id string) (*SimpleResponse, error) {
 		}
 	}
 
-	return &SimpleResponse{Result: "failed"}, nil
+	err := fmt.Errorf("unable to find load balancer %s", id)
+	return nil, DatabaseLoadBalancerNotFoundError.wrap(err)
 }
 
 // ListKubernetesClusterPools implemented in a fake way for automated tests
 func (c *FakeClient) ListKubernetesClusterPools(cid string) ([]KubernetesPool, error) {
+	c.applyTransitions()
 	pools := []KubernetesPool{}
 	found := false
 
@@ -1804,6 +2148,7 @@ func (c *FakeClient) ListKubernetesClusterPools(cid string) ([]KubernetesPool, e
 
 // GetKubernetesClusterPool implemented in a fake way for automated tests
 func (c *FakeClient) GetKubernetesClusterPool(cid, pid string) (*KubernetesPool, error) {
+	c.applyTransitions()
 	pool := &KubernetesPool{}
 	clusterFound := false
 	poolFound := false
@@ -1836,34 +2181,14 @@ func (c *FakeClient) GetKubernetesClusterPool(cid, pid string) (*KubernetesPool,
 
 // FindKubernetesClusterPool implemented in a fake way for automated tests
 func (c *FakeClient) FindKubernetesClusterPool(cid, search string) (*KubernetesPool, error) {
-	pool := &KubernetesPool{}
-	clusterFound := false
-	poolFound := false
-
 	for _, cs := range c.Clusters {
 		if cs.ID == cid {
-			clusterFound = true
-			for _, p := range cs.Pools {
-				if p.ID == search || strings.Contains(p.ID, search) {
-					poolFound = true
-					pool = &p
-					break
-				}
-			}
+			return findMatch(cs.Pools, search, c.FindMode, func(p KubernetesPool) string { return p.ID }, func(p KubernetesPool) []string { return nil })
 		}
 	}
 
-	if !clusterFound {
-		err := fmt.Errorf("unable to get kubernetes cluster %s", cid)
-		return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
-	}
-
-	if !poolFound {
-		err := fmt.Errorf("unable to get kubernetes pool %s", search)
-		return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
-	}
-
-	return pool, nil
+	err := fmt.Errorf("unable to get kubernetes cluster %s", cid)
+	return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
 }
 
 // DeleteKubernetesClusterPoolInstance implemented in a fake way for automated tests
@@ -1888,7 +2213,14 @@ func (c *FakeClient) DeleteKubernetesClusterPoolInstance(cid, pid, id string) (*
 								instanceNames = append(instanceNames, in.Hostname)
 							}
 							p.InstanceNames = instanceNames
+							p.Count = len(p.Instances)
 							c.Clusters[ci].Pools[pi] = p
+							for ki, node := range c.Clusters[ci].Instances {
+								if node.ID == id {
+									c.Clusters[ci].Instances = append(c.Clusters[ci].Instances[:ki], c.Clusters[ci].Instances[ki+1:]...)
+									break
+								}
+							}
 							break
 						}
 					}
@@ -1917,36 +2249,133 @@ func (c *FakeClient) DeleteKubernetesClusterPoolInstance(cid, pid, id string) (*
 	}, nil
 }
 
+// CreateKubernetesClusterPool implemented in a fake way for automated tests
+func (c *FakeClient) CreateKubernetesClusterPool(id string, i *KubernetesClusterPoolConfig) (*SimpleResponse, error) {
+	for ci, cs := range c.Clusters {
+		if cs.ID != id {
+			continue
+		}
+
+		pool := KubernetesPool{
+			ID:               i.ID,
+			Size:             i.Size,
+			Labels:           i.Labels,
+			Taints:           i.Taints,
+			PublicIPNodePool: i.PublicIPNodePool,
+			Instances:        []KubernetesInstance{},
+			InstanceNames:    []string{},
+		}
+		if pool.ID == "" {
+			pool.ID = c.generateID()
+		}
+		for _, p := range cs.Pools {
+			if p.ID == pool.ID {
+				err := fmt.Errorf("kubernetes pool %s already exists", pool.ID)
+				return nil, DatabaseKubernetesClusterDuplicateError.wrap(err)
+			}
+		}
+
+		c.Clusters[ci].Pools = append(c.Clusters[ci].Pools, pool)
+		c.scaleFakePool(ci, len(c.Clusters[ci].Pools)-1, i.Count)
+		return &SimpleResponse{Result: "success"}, nil
+	}
+
+	err := fmt.Errorf("unable to get kubernetes cluster %s", id)
+	return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
+}
+
 // UpdateKubernetesClusterPool implemented in a fake way for automated tests
 func (c *FakeClient) UpdateKubernetesClusterPool(cid, pid string, config *KubernetesClusterPoolUpdateConfig) (*KubernetesPool, error) {
-	clusterFound := false
-	poolFound := false
+	for ci, cs := range c.Clusters {
+		if cs.ID != cid {
+			continue
+		}
 
-	pool := KubernetesPool{}
-	for _, cs := range c.Clusters {
-		if cs.ID == cid {
-			clusterFound = true
-			for _, p := range cs.Pools {
-				if p.ID == pid {
-					poolFound = true
-					p.Count = *config.Count
-					pool = p
-				}
+		for pi, p := range cs.Pools {
+			if p.ID != pid {
+				continue
+			}
+
+			if config.Labels != nil {
+				c.Clusters[ci].Pools[pi].Labels = config.Labels
+			}
+			c.Clusters[ci].Pools[pi].Taints = config.Taints
+			if config.PublicIPNodePool {
+				c.Clusters[ci].Pools[pi].PublicIPNodePool = true
+			}
+			if config.Count != nil {
+				c.scaleFakePool(ci, pi, *config.Count)
 			}
+
+			pool := c.Clusters[ci].Pools[pi]
+			return &pool, nil
 		}
-	}
 
-	if !clusterFound {
-		err := fmt.Errorf("unable to get kubernetes cluster %s", cid)
+		err := fmt.Errorf("unable to get kubernetes pool %s", pid)
 		return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
 	}
 
-	if !poolFound {
-		err := fmt.Errorf("unable to get kubernetes pool %s", pid)
+	err := fmt.Errorf("unable to get kubernetes cluster %s", cid)
+	return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
+}
+
+// DeleteKubernetesClusterPool implemented in a fake way for automated tests
+func (c *FakeClient) DeleteKubernetesClusterPool(id, poolID string) (*SimpleResponse, error) {
+	for ci, cs := range c.Clusters {
+		if cs.ID != id {
+			continue
+		}
+
+		for pi, p := range cs.Pools {
+			if p.ID != poolID {
+				continue
+			}
+
+			c.scaleFakePool(ci, pi, 0)
+			c.Clusters[ci].Pools = append(c.Clusters[ci].Pools[:pi], c.Clusters[ci].Pools[pi+1:]...)
+			return &SimpleResponse{Result: "success"}, nil
+		}
+
+		err := fmt.Errorf("unable to get kubernetes pool %s", poolID)
 		return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
 	}
 
-	return &pool, nil
+	err := fmt.Errorf("unable to get kubernetes cluster %s", id)
+	return nil, DatabaseKubernetesClusterNotFoundError.wrap(err)
+}
+
+// scaleFakePool sets the pool's count, adding nodes to or removing the newest
+// nodes from both the pool and its cluster
+func (c *FakeClient) scaleFakePool(ci, pi, count int) {
+	cluster := &c.Clusters[ci]
+	pool := &cluster.Pools[pi]
+	for len(pool.Instances) < count {
+		id := c.generateID()
+		instance := KubernetesInstance{
+			ID:       id,
+			Hostname: fmt.Sprintf("%s-%s-%s", cluster.Name, pool.ID, id),
+			Size:     pool.Size,
+			Status:   InstanceStatusActive,
+		}
+		pool.Instances = append(pool.Instances, instance)
+		cluster.Instances = append(cluster.Instances, instance)
+	}
+	for len(pool.Instances) > count {
+		removed := pool.Instances[len(pool.Instances)-1]
+		pool.Instances = pool.Instances[:len(pool.Instances)-1]
+		for i, instance := range cluster.Instances {
+			if instance.ID == removed.ID {
+				cluster.Instances = append(cluster.Instances[:i], cluster.Instances[i+1:]...)
+				break
+			}
+		}
+	}
+
+	pool.Count = count
+	pool.InstanceNames = []string{}
+	for _, instance := range pool.Instances {
+		pool.InstanceNames = append(pool.InstanceNames, instance.Hostname)
+	}
 }
 
 // ListIPs returns a list of fake IPs
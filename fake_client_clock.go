@@ -0,0 +1,238 @@
+package civogo
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FakeClock is a virtual clock which only moves when advanced. Setting it as
+// FakeClient.Clock makes the fake simulate asynchronous operations: new instances
+// are BUILDING until FakeClient.InstanceBuildDuration has passed, new clusters aren't
+// Ready until FakeClient.ClusterBuildDuration has passed and deleted instances and
+// clusters stay DELETING for FakeClient.DeletionDuration before they disappear.
+// Starting, stopping, rebooting and resizing an instance takes FakeClient.ActionDuration,
+// upgrading a cluster's Kubernetes version and recycling a node take FakeClient.ClusterBuildDuration.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// fakeTransition is a change to the FakeClient's state that happens once the clock reaches at
+type fakeTransition struct {
+	at    time.Time
+	apply func()
+}
+
+// NewFakeClock returns a FakeClock starting at start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the current virtual time
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the virtual time forward by d
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Sleep advances the clock by d and returns immediately, so it can replace
+// time.Sleep in polling loops under test
+func (f *FakeClock) Sleep(d time.Duration) {
+	f.Advance(d)
+}
+
+// after schedules apply to run once the clock has moved on by d, returning false
+// if there is no clock and the change should be made straight away
+func (c *FakeClient) after(d time.Duration, apply func()) bool {
+	if c.Clock == nil {
+		return false
+	}
+	c.pending = append(c.pending, fakeTransition{at: c.Clock.Now().Add(d), apply: apply})
+	return true
+}
+
+// applyTransitions makes every scheduled change that is due, in the order they were scheduled
+func (c *FakeClient) applyTransitions() {
+	if c.Clock == nil || len(c.pending) == 0 {
+		return
+	}
+
+	now := c.Clock.Now()
+	remaining := []fakeTransition{}
+	for _, t := range c.pending {
+		if t.at.After(now) {
+			remaining = append(remaining, t)
+			continue
+		}
+		t.apply()
+	}
+	c.pending = remaining
+}
+
+func (c *FakeClient) setInstanceStatus(id, status string) {
+	for i, instance := range c.Instances {
+		if instance.ID == id {
+			c.Instances[i].Status = status
+		}
+	}
+}
+
+func (c *FakeClient) removeInstance(id string) {
+	for i, instance := range c.Instances {
+		if instance.ID == id {
+			c.Instances = append(c.Instances[:i], c.Instances[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *FakeClient) setClusterReady(id string, ready bool) {
+	clusterStatus := "BUILDING"
+	if ready {
+		clusterStatus = "ACTIVE"
+	}
+	c.setClusterStatus(id, clusterStatus)
+	c.setClusterConditions(id, ready)
+}
+
+// setClusterConditions sets Ready and every condition, leaving the status alone
+func (c *FakeClient) setClusterConditions(id string, ready bool) {
+	status := metav1.ConditionFalse
+	if ready {
+		status = metav1.ConditionTrue
+	}
+
+	for i, cluster := range c.Clusters {
+		if cluster.ID != id {
+			continue
+		}
+
+		var now time.Time
+		if c.Clock != nil {
+			now = c.Clock.Now()
+		}
+
+		c.Clusters[i].Ready = ready
+		c.Clusters[i].Conditions = []Condition{}
+		for _, conditionType := range KubernetesConditionTypes {
+			c.Clusters[i].Conditions = append(c.Clusters[i].Conditions, Condition{
+				Type:               conditionType,
+				Status:             status,
+				Synced:             ready,
+				LastTransitionTime: metav1.NewTime(now),
+			})
+		}
+	}
+}
+
+func (c *FakeClient) setClusterStatus(id, status string) {
+	for i, cluster := range c.Clusters {
+		if cluster.ID == id {
+			c.Clusters[i].Status = status
+			c.Clusters[i].Ready = false
+		}
+	}
+}
+
+func (c *FakeClient) removeCluster(id string) {
+	for i, cluster := range c.Clusters {
+		if cluster.ID == id {
+			c.Clusters = append(c.Clusters[:i], c.Clusters[i+1:]...)
+			return
+		}
+	}
+}
+
+// upgradeCluster moves the cluster to version. When there's a clock it stays
+// ACTIVE, with its conditions false, for FakeClient.ClusterBuildDuration while
+// its nodes finish one after another.
+func (c *FakeClient) upgradeCluster(id, version string) {
+	finish := func() {
+		for i, cluster := range c.Clusters {
+			if cluster.ID == id {
+				c.Clusters[i].KubernetesVersion = version
+				c.Clusters[i].Version = version
+			}
+		}
+		c.setClusterConditions(id, true)
+	}
+	if c.Clock == nil {
+		finish()
+		return
+	}
+
+	c.setClusterConditions(id, false)
+	nodes := []string{}
+	for _, cluster := range c.Clusters {
+		if cluster.ID == id {
+			for _, instance := range cluster.Instances {
+				nodes = append(nodes, instance.ID)
+			}
+		}
+	}
+	for i, node := range nodes {
+		node := node
+		c.setInstanceStatus(node, InstanceStatusRebooting)
+		c.after(c.ClusterBuildDuration*time.Duration(i+1)/time.Duration(len(nodes)+1), func() {
+			c.setInstanceStatus(node, InstanceStatusActive)
+		})
+	}
+	c.after(c.ClusterBuildDuration, finish)
+}
+
+// recycleNode replaces the node with a new instance with the same hostname.
+// When there's a clock the old node is DELETING for the first half of
+// FakeClient.ClusterBuildDuration and the new one is BUILDING for the second.
+func (c *FakeClient) recycleNode(clusterID, nodeID string) {
+	replacementID := c.generateID()
+	replace := func() {
+		for ci, cluster := range c.Clusters {
+			if cluster.ID != clusterID {
+				continue
+			}
+			for i, node := range cluster.Instances {
+				if node.ID == nodeID {
+					c.Clusters[ci].Instances[i].ID = replacementID
+				}
+			}
+			for pi, pool := range cluster.Pools {
+				for i, node := range pool.Instances {
+					if node.ID == nodeID {
+						c.Clusters[ci].Pools[pi].Instances[i].ID = replacementID
+					}
+				}
+			}
+		}
+		for i, instance := range c.Instances {
+			if instance.ID == nodeID {
+				c.Instances[i].ID = replacementID
+				c.Instances[i].Status = InstanceStatusActive
+				if c.Clock != nil {
+					c.Instances[i].Status = InstanceStatusBuilding
+				}
+			}
+		}
+	}
+	if c.Clock == nil {
+		replace()
+		return
+	}
+
+	c.setInstanceStatus(nodeID, InstanceStatusDeleting)
+	c.setClusterReady(clusterID, false)
+	c.setClusterStatus(clusterID, "ACTIVE")
+	c.after(c.ClusterBuildDuration/2, replace)
+	c.after(c.ClusterBuildDuration, func() {
+		c.setInstanceStatus(replacementID, InstanceStatusActive)
+		c.setClusterReady(clusterID, true)
+	})
+}
@@ -0,0 +1,100 @@
+package civogo
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFakeClientInstanceTransitions(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.Clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client.InstanceBuildDuration = 2 * time.Minute
+	client.DeletionDuration = 30 * time.Second
+
+	instance, err := client.CreateInstance(&InstanceConfig{Hostname: "web-1"})
+	g.Expect(err).To(BeNil())
+	g.Expect(instance.Status).To(Equal("BUILDING"))
+
+	client.Clock.Advance(time.Minute)
+	got, err := client.GetInstance(instance.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Status).To(Equal("BUILDING"))
+
+	client.Clock.Sleep(time.Minute)
+	got, err = client.GetInstance(instance.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Status).To(Equal("ACTIVE"))
+
+	_, err = client.DeleteInstance(instance.ID)
+	g.Expect(err).To(BeNil())
+	got, err = client.GetInstance(instance.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Status).To(Equal("DELETING"))
+
+	client.Clock.Advance(30 * time.Second)
+	_, err = client.GetInstance(instance.ID)
+	g.Expect(err).ToNot(BeNil())
+}
+
+func TestFakeClientClusterTransitions(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.Clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client.ClusterBuildDuration = 5 * time.Minute
+	client.DeletionDuration = time.Minute
+
+	cluster, err := client.NewKubernetesClusters(&KubernetesClusterConfig{Name: "prod", NumTargetNodes: 1})
+	g.Expect(err).To(BeNil())
+	g.Expect(cluster.Ready).To(BeFalse())
+	g.Expect(cluster.Status).To(Equal("BUILDING"))
+	g.Expect(cluster.Conditions).ToNot(BeEmpty())
+	for _, condition := range cluster.Conditions {
+		g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	}
+
+	client.Clock.Advance(5 * time.Minute)
+	got, err := client.GetKubernetesCluster(cluster.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Ready).To(BeTrue())
+	g.Expect(got.Status).To(Equal("ACTIVE"))
+	for _, condition := range got.Conditions {
+		g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		g.Expect(condition.LastTransitionTime.Time).To(Equal(client.Clock.Now()))
+	}
+
+	_, err = client.DeleteKubernetesCluster(cluster.ID)
+	g.Expect(err).To(BeNil())
+	clusters, err := client.ListKubernetesClusters()
+	g.Expect(err).To(BeNil())
+	g.Expect(clusters.Items).To(HaveLen(1))
+	g.Expect(clusters.Items[0].Status).To(Equal("DELETING"))
+
+	client.Clock.Advance(time.Minute)
+	clusters, err = client.ListKubernetesClusters()
+	g.Expect(err).To(BeNil())
+	g.Expect(clusters.Items).To(BeEmpty())
+}
+
+func TestFakeClientWithoutClockIsImmediate(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+
+	instance, err := client.CreateInstance(&InstanceConfig{Hostname: "web-1"})
+	g.Expect(err).To(BeNil())
+	g.Expect(instance.Status).To(Equal("ACTIVE"))
+
+	cluster, err := client.NewKubernetesClusters(&KubernetesClusterConfig{Name: "prod"})
+	g.Expect(err).To(BeNil())
+	g.Expect(cluster.Ready).To(BeTrue())
+
+	_, err = client.DeleteInstance(instance.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(client.Instances).To(BeEmpty())
+}
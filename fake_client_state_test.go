@@ -0,0 +1,102 @@
+package civogo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+const fakeClientStateFixture = `{
+  "version": 1,
+  "instances": [
+    {"id": "3", "hostname": "web-1", "size": "g3.small", "status": "ACTIVE", "tags": ["web"], "added_by_a_newer_api": true}
+  ],
+  "kubernetes_clusters": [
+    {
+      "id": "7",
+      "name": "prod",
+      "status": "ACTIVE",
+      "pools": [{"id": "8", "count": 1, "size": "g4s.kube.small", "instances": [{"id": "9", "hostname": "prod-node-1"}]}],
+      "conditions": []
+    }
+  ],
+  "dns_domains": [{"id": "4", "name": "example.com"}],
+  "dns_records": [{"id": "5", "domain_id": "4", "name": "www", "type": "A", "value": "1.2.3.4"}],
+  "firewalls": [{"id": "1", "name": "default", "instance_count": 1, "cluster_count": 0, "loadbalancer_count": 0}],
+  "load_balancers": [{"id": "6", "name": "lb", "algorithm": "round_robin", "public_ip": "5.6.7.8", "private_ip": "", "firewall_id": "1", "cluster_id": "7", "state": "available"}]
+}`
+
+func TestFakeClientLoadState(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	err := client.LoadState(strings.NewReader(fakeClientStateFixture))
+	g.Expect(err).To(BeNil())
+
+	g.Expect(client.LastID).To(Equal(int64(9)))
+	g.Expect(client.Instances).To(HaveLen(1))
+	g.Expect(client.Clusters[0].Pools[0].Instances[0].Hostname).To(Equal("prod-node-1"))
+	g.Expect(client.LoadBalancers[0].ClusterID).To(Equal("7"))
+
+	domain, err := client.FindDNSDomain("example")
+	g.Expect(err).To(BeNil())
+	g.Expect(domain.ID).To(Equal("4"))
+
+	created, err := client.CreateDNSDomain("example.net")
+	g.Expect(err).To(BeNil())
+	g.Expect(created.ID).To(Equal("10"))
+}
+
+func TestFakeClientDumpStateRoundTrip(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	g.Expect(client.LoadState(strings.NewReader(fakeClientStateFixture))).To(Succeed())
+	_, err := client.CreateDNSDomain("example.net")
+	g.Expect(err).To(BeNil())
+
+	first := bytes.Buffer{}
+	g.Expect(client.DumpState(&first)).To(Succeed())
+	g.Expect(first.String()).To(ContainSubstring(`"version": 1`))
+	g.Expect(first.String()).To(ContainSubstring(`"last_id": 10`))
+	g.Expect(first.String()).To(ContainSubstring(`"ssh_keys": []`))
+
+	restored, _ := NewFakeClient()
+	g.Expect(restored.LoadState(bytes.NewReader(first.Bytes()))).To(Succeed())
+	g.Expect(restored.Domains).To(HaveLen(2))
+
+	second := bytes.Buffer{}
+	g.Expect(restored.DumpState(&second)).To(Succeed())
+	g.Expect(second.String()).To(Equal(first.String()))
+}
+
+func TestFakeClientLoadStateErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	err := client.LoadState(strings.NewReader(`{"version": 2}`))
+	g.Expect(err).To(MatchError(ContainSubstring("unsupported fake client state version 2")))
+
+	err = client.LoadState(strings.NewReader(`{"version": 1, "servers": []}`))
+	g.Expect(err).To(MatchError(`unable to decode fake client state: unknown field "servers"`))
+}
+
+func TestFakeClientLoadStateDropsPendingTransitions(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.Clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client.InstanceBuildDuration = time.Minute
+	instance, _ := client.CreateInstance(&InstanceConfig{Hostname: "web"})
+	g.Expect(instance.ID).To(Equal("1"))
+
+	// The loaded instance shares the ID of the one still building, but isn't changed by it finishing
+	g.Expect(client.LoadState(strings.NewReader(`{"instances": [{"id": "1", "hostname": "db", "status": "SHUTOFF"}]}`))).To(Succeed())
+	client.Clock.Advance(time.Hour)
+	loaded, err := client.GetInstance("1")
+	g.Expect(err).To(BeNil())
+	g.Expect(loaded.Status).To(Equal(InstanceStatusShutoff))
+}
@@ -0,0 +1,78 @@
+package civogo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FindMode controls how the Find* methods match their search term
+type FindMode int
+
+const (
+	// FindModeDefault tries an exact ID, an exact name, a case-insensitive name,
+	// a case-insensitive prefix and finally a case-insensitive substring, stopping
+	// at the first of those which matches anything
+	FindModeDefault FindMode = iota
+	// FindModeExact only accepts an exact ID or an exact name, which is safer for
+	// automation where a partial match could pick the wrong resource
+	FindModeExact
+)
+
+// findMatch returns the single item matched by search, trying each matching rule
+// in turn. If a rule matches more than one item the candidates are listed in the
+// MultipleMatchesError rather than falling back to a looser rule.
+func findMatch[T any](items []T, search string, mode FindMode, id func(T) string, names func(T) []string) (*T, error) {
+	rules := []func(key, search string) bool{
+		func(key, search string) bool { return key == search },
+		func(key, search string) bool { return strings.EqualFold(key, search) },
+		func(key, search string) bool { return strings.HasPrefix(strings.ToLower(key), strings.ToLower(search)) },
+		func(key, search string) bool { return strings.Contains(strings.ToLower(key), strings.ToLower(search)) },
+	}
+	if mode == FindModeExact {
+		rules = rules[:1]
+	}
+
+	// An exact ID always wins over any name
+	for _, item := range items {
+		if id(item) != "" && id(item) == search {
+			return &item, nil
+		}
+	}
+
+	for _, rule := range rules {
+		matches := []int{}
+		for i, item := range items {
+			keys := append([]string{id(item)}, names(item)...)
+			for _, key := range keys {
+				if key != "" && rule(key, search) {
+					matches = append(matches, i)
+					break
+				}
+			}
+		}
+
+		if len(matches) == 1 {
+			return &items[matches[0]], nil
+		} else if len(matches) > 1 {
+			candidates := []string{}
+			for _, i := range matches {
+				candidates = append(candidates, findCandidate(id(items[i]), names(items[i])))
+			}
+			err := fmt.Errorf("unable to find %s because there were multiple matches: %s", search, strings.Join(candidates, ", "))
+			return nil, MultipleMatchesError.wrap(err)
+		}
+	}
+
+	err := fmt.Errorf("unable to find %s, zero matches", search)
+	return nil, ZeroMatchesError.wrap(err)
+}
+
+// findCandidate describes an item in a MultipleMatchesError as "name (id)"
+func findCandidate(id string, names []string) string {
+	for _, name := range names {
+		if name != "" && name != id {
+			return fmt.Sprintf("%s (%s)", name, id)
+		}
+	}
+	return id
+}
@@ -0,0 +1,84 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFindMatchRules(t *testing.T) {
+	g := NewWithT(t)
+
+	networks := []Network{
+		{ID: "12345", Label: "frontend"},
+		{ID: "23456", Label: "Frontend-Staging"},
+		{ID: "34567", Label: "backend"},
+		{ID: "45678", Label: "12345"},
+	}
+	find := func(search string, mode FindMode) (*Network, error) {
+		return findMatch(networks, search, mode,
+			func(n Network) string { return n.ID },
+			func(n Network) []string { return []string{n.Label} },
+		)
+	}
+
+	tests := []struct {
+		search string
+		id     string
+	}{
+		{"12345", "12345"},            // an exact ID beats a name equal to it
+		{"frontend", "12345"},         // an exact name beats a prefix
+		{"FRONTEND-staging", "23456"}, // case-insensitive name
+		{"back", "34567"},             // prefix
+		{"STAG", "23456"},             // substring
+		{"234", "23456"},              // ID prefix beats ID substring
+	}
+	for _, test := range tests {
+		got, err := find(test.search, FindModeDefault)
+		g.Expect(err).To(BeNil(), test.search)
+		g.Expect(got.ID).To(Equal(test.id), test.search)
+	}
+
+	_, err := find("end", FindModeDefault)
+	g.Expect(errors.Is(err, MultipleMatchesError)).To(BeTrue())
+	g.Expect(err.Error()).To(Equal("MultipleMatchesError: unable to find end because there were multiple matches: frontend (12345), Frontend-Staging (23456), backend (34567)"))
+
+	_, err = find("database", FindModeDefault)
+	g.Expect(err.Error()).To(Equal("ZeroMatchesError: unable to find database, zero matches"))
+}
+
+func TestFindMatchExactMode(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.FindMode = FindModeExact
+	web, _ := client.CreateInstance(&InstanceConfig{Hostname: "web.example.com"})
+
+	got, err := client.FindInstance("web.example.com")
+	g.Expect(err).To(BeNil())
+	g.Expect(got.ID).To(Equal(web.ID))
+	got, err = client.FindInstance(web.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.ID).To(Equal(web.ID))
+
+	for _, search := range []string{"web", "WEB.example.com", "example"} {
+		_, err = client.FindInstance(search)
+		g.Expect(errors.Is(err, ZeroMatchesError)).To(BeTrue(), search)
+	}
+}
+
+func TestFindKubernetesClusterPoolByPrefix(t *testing.T) {
+	client, server, _ := NewClientForTesting(map[string]string{
+		"/v2/kubernetes/clusters/69a23478/pools": `[{"id": "8382e422-dcdd-461f-afb4-2ab67f171c3e", "count": 3}, {"id": "2ab67f17-461f-dcdd-afb4-8382e4221c3e", "count": 1}]`,
+	})
+	defer server.Close()
+
+	got, err := client.FindKubernetesClusterPool("69a23478", "2ab6")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if got.ID != "2ab67f17-461f-dcdd-afb4-8382e4221c3e" {
+		t.Errorf("Expected %s, got %s", "2ab67f17-461f-dcdd-afb4-8382e4221c3e", got.ID)
+	}
+}
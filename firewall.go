@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 )
 
 // Firewall represents list of rule in Civo's infrastructure
@@ -88,31 +87,10 @@ func (c *Client) FindFirewall(search string) (*Firewall, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Firewall{}
-
-	for _, value := range firewalls {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(firewalls, search, c.FindMode,
+		func(f Firewall) string { return f.ID },
+		func(f Firewall) []string { return []string{f.Name} },
+	)
 }
 
 // NewFirewall creates a new firewall record
@@ -195,24 +173,7 @@ func (c *Client) FindFirewallRule(firewallID string, search string) (*FirewallRu
 		return nil, decodeError(err)
 	}
 
-	found := -1
-
-	for i, firewallRule := range firewallsRules {
-		if strings.Contains(firewallRule.ID, search) {
-			if found != -1 {
-				err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-				return nil, MultipleMatchesError.wrap(err)
-			}
-			found = i
-		}
-	}
-
-	if found == -1 {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
-
-	return &firewallsRules[found], nil
+	return findMatch(firewallsRules, search, c.FindMode, func(r FirewallRule) string { return r.ID }, func(r FirewallRule) []string { return nil })
 }
 
 // DeleteFirewallRule deletes an firewall
@@ -49,8 +49,8 @@ func TestFindFirewall(t *testing.T) {
 	}
 
 	_, err := client.FindFirewall("web")
-	if err.Error() != "MultipleMatchesError: unable to find web because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find web because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find web because there were multiple matches: web-instance (12345), web-node (67789)" {
+		t.Errorf("Expected %s, got %s", "unable to find web because there were multiple matches: web-instance (12345), web-node (67789)", err.Error())
 	}
 
 	_, err = client.FindFirewall("missing")
@@ -310,8 +310,8 @@ func TestFindFirewallRule(t *testing.T) {
 	}
 
 	_, err := client.FindFirewallRule("22", "2")
-	if err.Error() != "MultipleMatchesError: unable to find 2 because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find 2 because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find 2 because there were multiple matches: 21, 22" {
+		t.Errorf("Expected %s, got %s", "unable to find 2 because there were multiple matches: 21, 22", err.Error())
 	}
 
 	_, err = client.FindFirewallRule("22", "missing")
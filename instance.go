@@ -169,27 +169,10 @@ func (c *Client) FindInstance(search string) (*Instance, error) {
 		return nil, decodeError(err)
 	}
 
-	partialMatchesCount := 0
-	result := Instance{}
-
-	for _, value := range instances {
-		if value.Hostname == search || value.ID == search {
-			return &value, nil
-		} else if strings.Contains(value.Hostname, search) || strings.Contains(value.ID, search) {
-			partialMatchesCount++
-			result = value
-		}
-	}
-
-	if partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(instances, search, c.FindMode,
+		func(i Instance) string { return i.ID },
+		func(i Instance) []string { return []string{i.Hostname} },
+	)
 }
 
 // GetInstance returns a single Instance by its full ID
@@ -464,8 +447,13 @@ func (c *Client) GetRecoveryStatus(id string) (*SimpleResponse, error) {
 	return c.DecodeSimpleResponse(resp)
 }
 
-// UpdateInstanceAllowedIPs sets the list of IP addresses that an instance is allowed to use
+// UpdateInstanceAllowedIPs sets the list of IP addresses that an instance is allowed to use,
+// returning ParameterAllowedIPInvalidError without sending anything if one isn't valid
 func (c *Client) UpdateInstanceAllowedIPs(id string, allowedIPs []string) (*SimpleResponse, error) {
+	allowedIPs, err := NormalizeAllowedIPs(allowedIPs)
+	if err != nil {
+		return nil, err
+	}
 	// Create a map to match the expected JSON structure
 	payload := map[string][]string{
 		"allowed_ips": allowedIPs,
@@ -479,8 +467,12 @@ func (c *Client) UpdateInstanceAllowedIPs(id string, allowedIPs []string) (*Simp
 	return c.DecodeSimpleResponse(resp)
 }
 
-// UpdateInstanceBandwidth sets the list of IP addresses that an instance is allowed to use
+// UpdateInstanceBandwidth sets the instance's network bandwidth limit in Mbps,
+// returning ParameterBandwidthInvalidError if it's below 1Mbps
 func (c *Client) UpdateInstanceBandwidth(id string, bandwidthLimit int) (*SimpleResponse, error) {
+	if err := validateBandwidthLimit(bandwidthLimit); err != nil {
+		return nil, err
+	}
 	payload := map[string]int{
 		"network_bandwidth_limit": bandwidthLimit,
 	}
@@ -0,0 +1,197 @@
+package civogo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// DefaultCreateInstancesConcurrency is how many instances CreateInstances creates at once by default
+const DefaultCreateInstancesConcurrency = 5
+
+// CreateInstancesOptions controls how CreateInstances creates the instances
+type CreateInstancesOptions struct {
+	// Concurrency is the maximum number of create requests in flight at once,
+	// defaulting to DefaultCreateInstancesConcurrency
+	Concurrency int
+	// Rollback deletes the instances which were created if any of the others fail
+	Rollback bool
+}
+
+// InstanceHostnameData is passed to the InstanceConfig.Hostname template by CreateInstances
+type InstanceHostnameData struct {
+	// Index is the position of the instance being created, starting at 1
+	Index int
+	// Count is the total number of instances being created
+	Count int
+}
+
+// InstanceCreateFailure describes an instance CreateInstances was unable to create
+type InstanceCreateFailure struct {
+	Index    int
+	Hostname string
+	Err      error
+}
+
+// CreateInstancesError is returned by CreateInstances when some of the instances
+// couldn't be created. It matches InstancesCreateFailedError with errors.Is.
+type CreateInstancesError struct {
+	// Created are the instances which were created and still exist
+	Created []Instance
+	// Failed are the instances which couldn't be created
+	Failed []InstanceCreateFailure
+	// RolledBack are the created instances which were deleted again
+	RolledBack []Instance
+	// RollbackErrors holds the errors deleting created instances, keyed by instance ID
+	RollbackErrors map[string]error
+}
+
+func (e *CreateInstancesError) Error() string {
+	failures := []string{}
+	for _, failure := range e.Failed {
+		failures = append(failures, fmt.Sprintf("%s: %v", failure.Hostname, failure.Err))
+	}
+
+	total := len(e.Created) + len(e.RolledBack) + len(e.Failed)
+	msg := fmt.Sprintf("%s: unable to create %d of %d instances (%s)", InstancesCreateFailedError, len(e.Failed), total, strings.Join(failures, "; "))
+	if len(e.RolledBack) > 0 {
+		msg += fmt.Sprintf(", deleted %d created instances", len(e.RolledBack))
+	}
+	if len(e.Created) > 0 {
+		msg += fmt.Sprintf(", %d created instances remain", len(e.Created))
+	}
+	return msg
+}
+
+// Is makes errors.Is(err, InstancesCreateFailedError) true
+func (e *CreateInstancesError) Is(target error) bool {
+	return InstancesCreateFailedError.Is(target)
+}
+
+// Unwrap returns the errors for each instance which failed to be created
+func (e *CreateInstancesError) Unwrap() []error {
+	errs := []error{}
+	for _, failure := range e.Failed {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
+
+// CreateInstances creates config.Count instances, returning them in order. The
+// API only returns one instance per create request, so each instance is created
+// with its own request, up to options.Concurrency at once. The hostname may be a
+// template such as "web-{{.Index}}", see InstanceHostnameData; it must produce a
+// different hostname for each instance. If any instance fails a *CreateInstancesError
+// is returned describing exactly which were created, which failed and, if
+// options.Rollback is set, which were deleted again.
+func (c *Client) CreateInstances(config *InstanceConfig, options *CreateInstancesOptions) ([]Instance, error) {
+	create := func(config *InstanceConfig) (*Instance, error) {
+		return c.clone().CreateInstance(config)
+	}
+
+	return createInstances(config, options, create, func(id string) error {
+		_, err := c.DeleteInstance(id)
+		return err
+	})
+}
+
+// createInstances implements CreateInstances on top of single create and delete calls
+func createInstances(config *InstanceConfig, options *CreateInstancesOptions, create func(*InstanceConfig) (*Instance, error), remove func(id string) error) ([]Instance, error) {
+	if options == nil {
+		options = &CreateInstancesOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultCreateInstancesConcurrency
+	}
+
+	hostnames, err := instanceHostnames(config.Hostname, config.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]*Instance, len(hostnames))
+	errs := make([]error, len(hostnames))
+	semaphore := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+
+	for i, hostname := range hostnames {
+		instanceConfig := *config
+		instanceConfig.Count = 1
+		instanceConfig.Hostname = hostname
+		instanceConfig.Tags = append([]string{}, config.Tags...)
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			instances[i], errs[i] = create(&instanceConfig)
+		}(i)
+	}
+	wg.Wait()
+
+	result := &CreateInstancesError{}
+	for i, hostname := range hostnames {
+		if errs[i] != nil {
+			result.Failed = append(result.Failed, InstanceCreateFailure{Index: i + 1, Hostname: hostname, Err: errs[i]})
+			continue
+		}
+		result.Created = append(result.Created, *instances[i])
+	}
+
+	if len(result.Failed) == 0 {
+		return result.Created, nil
+	}
+
+	if options.Rollback {
+		remaining := []Instance{}
+		for _, instance := range result.Created {
+			if err := remove(instance.ID); err != nil {
+				if result.RollbackErrors == nil {
+					result.RollbackErrors = map[string]error{}
+				}
+				result.RollbackErrors[instance.ID] = err
+				remaining = append(remaining, instance)
+				continue
+			}
+			result.RolledBack = append(result.RolledBack, instance)
+		}
+		result.Created = remaining
+	}
+
+	return result.Created, result
+}
+
+// instanceHostnames renders the hostname template for each of count instances
+func instanceHostnames(hostname string, count int) ([]string, error) {
+	if count < 1 {
+		count = 1
+	}
+
+	tmpl, err := template.New("hostname").Option("missingkey=error").Parse(hostname)
+	if err != nil {
+		return nil, ParameterHostnameTemplateInvalidError.wrap(err)
+	}
+
+	hostnames := []string{}
+	seen := map[string]bool{}
+	for i := 1; i <= count; i++ {
+		buf := bytes.Buffer{}
+		if err := tmpl.Execute(&buf, InstanceHostnameData{Index: i, Count: count}); err != nil {
+			return nil, ParameterHostnameTemplateInvalidError.wrap(err)
+		}
+
+		name := buf.String()
+		if name != "" && seen[name] {
+			err := fmt.Errorf("hostname %q is repeated, use {{.Index}} to create %d instances", name, count)
+			return nil, ParameterHostnameTemplateInvalidError.wrap(err)
+		}
+		seen[name] = true
+		hostnames = append(hostnames, name)
+	}
+
+	return hostnames, nil
+}
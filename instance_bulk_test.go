@@ -0,0 +1,141 @@
+package civogo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCreateInstances(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	instances, err := client.CreateInstances(&InstanceConfig{Count: 3, Hostname: "web-{{.Index}}", Tags: []string{"web"}}, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(instances).To(HaveLen(3))
+	for i, hostname := range []string{"web-1", "web-2", "web-3"} {
+		g.Expect(instances[i].Hostname).To(Equal(hostname))
+		g.Expect(instances[i].Tags).To(Equal([]string{"web"}))
+	}
+	g.Expect(client.Instances).To(HaveLen(3))
+
+	// A single instance doesn't need a template
+	instances, err = client.CreateInstances(&InstanceConfig{Hostname: "db.example.com"}, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(instances).To(HaveLen(1))
+	g.Expect(instances[0].Hostname).To(Equal("db.example.com"))
+}
+
+func TestCreateInstancesHostnameTemplate(t *testing.T) {
+	g := NewWithT(t)
+
+	hostnames, err := instanceHostnames("node-{{.Index}}-of-{{.Count}}.example.com", 2)
+	g.Expect(err).To(BeNil())
+	g.Expect(hostnames).To(Equal([]string{"node-1-of-2.example.com", "node-2-of-2.example.com"}))
+
+	client, _ := NewFakeClient()
+	_, err = client.CreateInstances(&InstanceConfig{Count: 2, Hostname: "web"}, nil)
+	g.Expect(errors.Is(err, ParameterHostnameTemplateInvalidError)).To(BeTrue())
+	_, err = client.CreateInstances(&InstanceConfig{Count: 2, Hostname: "web-{{.Index"}, nil)
+	g.Expect(errors.Is(err, ParameterHostnameTemplateInvalidError)).To(BeTrue())
+	_, err = client.CreateInstances(&InstanceConfig{Count: 2, Hostname: "web-{{.Number}}"}, nil)
+	g.Expect(errors.Is(err, ParameterHostnameTemplateInvalidError)).To(BeTrue())
+	g.Expect(client.Instances).To(BeEmpty())
+}
+
+// newBulkInstanceServer returns a server which creates instances, failing for the
+// given hostnames, and records the instances deleted
+func newBulkInstanceServer(t *testing.T, failures map[string]bool) (*httptest.Server, *[]string, *int32) {
+	mu := sync.Mutex{}
+	deleted := []string{}
+	var inFlight, maxInFlight int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/instances", func(rw http.ResponseWriter, req *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			highest := atomic.LoadInt32(&maxInFlight)
+			if current <= highest || atomic.CompareAndSwapInt32(&maxInFlight, highest, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		config := InstanceConfig{}
+		json.NewDecoder(req.Body).Decode(&config)
+		if failures[config.Hostname] {
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte(`{"code": "quota_limit_reached", "reason": "quota exceeded"}`))
+			return
+		}
+		json.NewEncoder(rw).Encode(Instance{ID: "id-" + config.Hostname, Hostname: config.Hostname})
+	})
+	mux.HandleFunc("DELETE /v2/instances/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		deleted = append(deleted, req.PathValue("id"))
+		rw.Write([]byte(`{"result": "success"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &deleted, &maxInFlight
+}
+
+func TestCreateInstancesConcurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	server, _, maxInFlight := newBulkInstanceServer(t, nil)
+	client, _ := NewClientForTestingWithServer(server)
+
+	instances, err := client.CreateInstances(&InstanceConfig{Count: 6, Hostname: "web-{{.Index}}"}, &CreateInstancesOptions{Concurrency: 2})
+	g.Expect(err).To(BeNil())
+	g.Expect(instances).To(HaveLen(6))
+	g.Expect(instances[5].ID).To(Equal("id-web-6"))
+	g.Expect(atomic.LoadInt32(maxInFlight)).To(BeNumerically("<=", 2))
+}
+
+func TestCreateInstancesPartialFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	server, deleted, _ := newBulkInstanceServer(t, map[string]bool{"web-2": true})
+	client, _ := NewClientForTestingWithServer(server)
+
+	instances, err := client.CreateInstances(&InstanceConfig{Count: 3, Hostname: "web-{{.Index}}"}, nil)
+	g.Expect(errors.Is(err, InstancesCreateFailedError)).To(BeTrue())
+	g.Expect(err.Error()).To(HavePrefix("InstancesCreateFailedError: unable to create 1 of 3 instances (web-2: "))
+	g.Expect(instances).To(HaveLen(2))
+
+	report := &CreateInstancesError{}
+	g.Expect(errors.As(err, &report)).To(BeTrue())
+	g.Expect(report.Failed).To(HaveLen(1))
+	g.Expect(report.Failed[0].Index).To(Equal(2))
+	g.Expect(report.Failed[0].Hostname).To(Equal("web-2"))
+	g.Expect(errors.Is(err, QuotaLimitReachedError)).To(BeTrue())
+	g.Expect(report.Created).To(HaveLen(2))
+	g.Expect(*deleted).To(BeEmpty())
+}
+
+func TestCreateInstancesRollback(t *testing.T) {
+	g := NewWithT(t)
+
+	server, deleted, _ := newBulkInstanceServer(t, map[string]bool{"web-3": true})
+	client, _ := NewClientForTestingWithServer(server)
+
+	instances, err := client.CreateInstances(&InstanceConfig{Count: 3, Hostname: "web-{{.Index}}"}, &CreateInstancesOptions{Rollback: true})
+	g.Expect(errors.Is(err, InstancesCreateFailedError)).To(BeTrue())
+	g.Expect(instances).To(BeEmpty())
+
+	report := &CreateInstancesError{}
+	g.Expect(errors.As(err, &report)).To(BeTrue())
+	g.Expect(report.RolledBack).To(HaveLen(2))
+	g.Expect(*deleted).To(ConsistOf("id-web-1", "id-web-2"))
+}
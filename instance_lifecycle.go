@@ -0,0 +1,270 @@
+package civogo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Instance statuses reported by the API
+const (
+	InstanceStatusBuilding  = "BUILDING"
+	InstanceStatusActive    = "ACTIVE"
+	InstanceStatusShutoff   = "SHUTOFF"
+	InstanceStatusStopping  = "STOPPING"
+	InstanceStatusStarting  = "STARTING"
+	InstanceStatusRebooting = "REBOOTING"
+	InstanceStatusResizing  = "RESIZING"
+	InstanceStatusDeleting  = "DELETING"
+	InstanceStatusError     = "ERROR"
+)
+
+// InstanceAction is an operation which changes an instance's power state or size
+type InstanceAction string
+
+// Actions InstanceLifecycle can perform
+const (
+	InstanceActionStart      InstanceAction = "start"
+	InstanceActionStop       InstanceAction = "stop"
+	InstanceActionReboot     InstanceAction = "reboot"
+	InstanceActionHardReboot InstanceAction = "hard reboot"
+	InstanceActionSoftReboot InstanceAction = "soft reboot"
+	InstanceActionUpgrade    InstanceAction = "upgrade"
+	InstanceActionRecover    InstanceAction = "recover"
+)
+
+// instanceTransitions holds the statuses each action can be performed from and
+// the status the instance settles in afterwards
+var instanceTransitions = map[InstanceAction]struct {
+	from []string
+	to   string
+}{
+	InstanceActionStart:      {from: []string{InstanceStatusShutoff}, to: InstanceStatusActive},
+	InstanceActionStop:       {from: []string{InstanceStatusActive}, to: InstanceStatusShutoff},
+	InstanceActionReboot:     {from: []string{InstanceStatusActive}, to: InstanceStatusActive},
+	InstanceActionHardReboot: {from: []string{InstanceStatusActive}, to: InstanceStatusActive},
+	InstanceActionSoftReboot: {from: []string{InstanceStatusActive}, to: InstanceStatusActive},
+	InstanceActionUpgrade:    {from: []string{InstanceStatusShutoff}, to: InstanceStatusShutoff},
+	InstanceActionRecover:    {from: []string{InstanceStatusActive, InstanceStatusShutoff}, to: InstanceStatusActive},
+}
+
+// InstanceTransitionError is returned when an action isn't valid for the
+// instance's current status. It matches InstanceInvalidTransitionError with errors.Is.
+type InstanceTransitionError struct {
+	InstanceID string
+	Action     InstanceAction
+	Status     string
+	Allowed    []string
+}
+
+func (e *InstanceTransitionError) Error() string {
+	return fmt.Sprintf("%s: unable to %s instance %s while it's %s, it must be %s",
+		InstanceInvalidTransitionError, e.Action, e.InstanceID, e.Status, strings.Join(e.Allowed, " or "))
+}
+
+// Is makes errors.Is(err, InstanceInvalidTransitionError) true
+func (e *InstanceTransitionError) Is(target error) bool {
+	return InstanceInvalidTransitionError.Is(target)
+}
+
+// CheckInstanceTransition returns an *InstanceTransitionError if action can't be
+// performed on the instance in its current status
+func CheckInstanceTransition(instance *Instance, action InstanceAction) error {
+	transition, ok := instanceTransitions[action]
+	if !ok {
+		err := fmt.Errorf("unknown instance action %q", action)
+		return InstanceInvalidTransitionError.wrap(err)
+	}
+
+	for _, status := range transition.from {
+		if instance.Status == status {
+			return nil
+		}
+	}
+	return &InstanceTransitionError{InstanceID: instance.ID, Action: action, Status: instance.Status, Allowed: transition.from}
+}
+
+// CheckInstanceUpgrade returns InstanceInvalidSizeError unless to is larger than
+// from: no smaller in CPU, RAM or disk and bigger in at least one of them
+func CheckInstanceUpgrade(from, to *InstanceSize) error {
+	if to.CPUCores < from.CPUCores || to.RAMMegabytes < from.RAMMegabytes || to.DiskGigabytes < from.DiskGigabytes {
+		err := fmt.Errorf("unable to upgrade from %s to %s, instances can't be downsized", from.Name, to.Name)
+		return InstanceInvalidSizeError.wrap(err)
+	}
+	if to.CPUCores == from.CPUCores && to.RAMMegabytes == from.RAMMegabytes && to.DiskGigabytes == from.DiskGigabytes {
+		err := fmt.Errorf("unable to upgrade from %s to %s, the new size isn't larger", from.Name, to.Name)
+		return InstanceInvalidSizeError.wrap(err)
+	}
+	return nil
+}
+
+// InstanceLifecycle starts, stops, reboots and resizes instances, checking each
+// action is valid for the instance's status first and waiting for the instance
+// to settle afterwards
+type InstanceLifecycle struct {
+	Client Clienter
+	// PollInterval is how often the instance is checked while waiting, defaulting to 5 seconds
+	PollInterval time.Duration
+	// Timeout is how long to wait for each action to finish, defaulting to 10 minutes
+	Timeout time.Duration
+	// Sleep and Now are used while waiting, when nil they're time.Sleep and time.Now
+	Sleep func(time.Duration)
+	Now   func() time.Time
+}
+
+// NewInstanceLifecycle returns an InstanceLifecycle using client with the default timings
+func NewInstanceLifecycle(client Clienter) *InstanceLifecycle {
+	return &InstanceLifecycle{Client: client}
+}
+
+// Start starts a stopped instance and waits for it to be ACTIVE
+func (l *InstanceLifecycle) Start(id string) (*Instance, error) {
+	return l.perform(id, InstanceActionStart, l.Client.StartInstance)
+}
+
+// Stop stops an active instance and waits for it to be SHUTOFF
+func (l *InstanceLifecycle) Stop(id string) (*Instance, error) {
+	return l.perform(id, InstanceActionStop, l.Client.StopInstance)
+}
+
+// Reboot reboots an active instance and waits for it to be ACTIVE again
+func (l *InstanceLifecycle) Reboot(id string) (*Instance, error) {
+	return l.perform(id, InstanceActionReboot, l.Client.RebootInstance)
+}
+
+// HardReboot hard reboots an active instance and waits for it to be ACTIVE again
+func (l *InstanceLifecycle) HardReboot(id string) (*Instance, error) {
+	return l.perform(id, InstanceActionHardReboot, l.Client.HardRebootInstance)
+}
+
+// SoftReboot soft reboots an active instance and waits for it to be ACTIVE again
+func (l *InstanceLifecycle) SoftReboot(id string) (*Instance, error) {
+	return l.perform(id, InstanceActionSoftReboot, l.Client.SoftRebootInstance)
+}
+
+// Upgrade resizes a stopped instance to a larger size and waits for the new size
+// to be reported, the instance is left stopped
+func (l *InstanceLifecycle) Upgrade(id, newSize string) (*Instance, error) {
+	instance, err := l.Client.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckInstanceTransition(instance, InstanceActionUpgrade); err != nil {
+		return nil, err
+	}
+	if err := l.checkUpgrade(instance.Size, newSize); err != nil {
+		return nil, err
+	}
+
+	if _, err := l.Client.UpgradeInstance(id, newSize); err != nil {
+		return nil, err
+	}
+	return l.waitFor(id, func(i *Instance) bool {
+		return i.Size == newSize && i.Status == InstanceStatusShutoff
+	}, fmt.Sprintf("size %s", newSize))
+}
+
+// Resize upgrades an instance to a larger size, stopping it first and starting it
+// again afterwards if it was running, even if the upgrade failed. The size is
+// checked before the instance is stopped.
+func (l *InstanceLifecycle) Resize(id, newSize string) (*Instance, error) {
+	instance, err := l.Client.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.checkUpgrade(instance.Size, newSize); err != nil {
+		return nil, err
+	}
+
+	wasActive := instance.Status == InstanceStatusActive
+	if wasActive {
+		if _, err := l.Stop(id); err != nil {
+			return nil, err
+		}
+	}
+
+	instance, err = l.Upgrade(id, newSize)
+	if !wasActive {
+		return instance, err
+	}
+	if err != nil {
+		if _, startErr := l.Start(id); startErr != nil {
+			err = errors.Join(err, fmt.Errorf("unable to start instance %s again: %w", id, startErr))
+		}
+		return instance, err
+	}
+	return l.Start(id)
+}
+
+// WaitForStatus polls the instance until it has the given status, returning
+// TimeoutError if that takes longer than Timeout
+func (l *InstanceLifecycle) WaitForStatus(id, status string) (*Instance, error) {
+	return l.waitFor(id, func(i *Instance) bool { return i.Status == status }, status)
+}
+
+func (l *InstanceLifecycle) perform(id string, action InstanceAction, call func(id string) (*SimpleResponse, error)) (*Instance, error) {
+	instance, err := l.Client.GetInstance(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckInstanceTransition(instance, action); err != nil {
+		return nil, err
+	}
+
+	if _, err := call(id); err != nil {
+		return nil, err
+	}
+	return l.WaitForStatus(id, instanceTransitions[action].to)
+}
+
+func (l *InstanceLifecycle) checkUpgrade(currentSize, newSize string) error {
+	sizes, err := l.Client.ListInstanceSizes()
+	if err != nil {
+		return err
+	}
+
+	var from, to *InstanceSize
+	for i := range sizes {
+		if sizes[i].Name == currentSize {
+			from = &sizes[i]
+		}
+		if sizes[i].Name == newSize {
+			to = &sizes[i]
+		}
+	}
+	if from == nil || to == nil {
+		err := fmt.Errorf("unable to compare sizes %s and %s, unknown size", currentSize, newSize)
+		return InstanceInvalidSizeError.wrap(err)
+	}
+	return CheckInstanceUpgrade(from, to)
+}
+
+func (l *InstanceLifecycle) waitFor(id string, done func(*Instance) bool, description string) (*Instance, error) {
+	var instance *Instance
+	err := l.poller().poll(func() (bool, error) {
+		var err error
+		if instance, err = l.Client.GetInstance(id); err != nil {
+			return false, err
+		}
+		if instance.Status == InstanceStatusError && !done(instance) {
+			err := fmt.Errorf("instance %s went into ERROR while waiting for %s", id, description)
+			return false, InstanceFailedError.wrap(err)
+		}
+		return done(instance), nil
+	}, func() error {
+		return fmt.Errorf("timed out waiting for instance %s to reach %s, it's %s", id, description, instance.Status)
+	})
+	return instance, err
+}
+
+func (l *InstanceLifecycle) poller() poller {
+	return poller{
+		interval:        l.PollInterval,
+		timeout:         l.Timeout,
+		defaultInterval: 5 * time.Second,
+		defaultTimeout:  10 * time.Minute,
+		sleep:           l.Sleep,
+		now:             l.Now,
+	}
+}
@@ -0,0 +1,122 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func newLifecycleForTesting() (*FakeClient, *InstanceLifecycle) {
+	client, _ := NewFakeClient()
+	client.Clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client.InstanceBuildDuration = time.Minute
+	client.ActionDuration = 30 * time.Second
+
+	lifecycle := NewInstanceLifecycle(client)
+	lifecycle.Sleep = client.Clock.Sleep
+	lifecycle.Now = client.Clock.Now
+	return client, lifecycle
+}
+
+func TestInstanceLifecycleStopStart(t *testing.T) {
+	g := NewWithT(t)
+
+	client, lifecycle := newLifecycleForTesting()
+	instance, _ := client.CreateInstance(&InstanceConfig{Hostname: "web", Size: "g3.xsmall"})
+
+	// Still BUILDING, so it can't be stopped yet
+	_, err := lifecycle.Stop(instance.ID)
+	g.Expect(errors.Is(err, InstanceInvalidTransitionError)).To(BeTrue())
+	g.Expect(err.Error()).To(Equal("InstanceInvalidTransitionError: unable to stop instance " + instance.ID + " while it's BUILDING, it must be ACTIVE"))
+
+	got, err := lifecycle.WaitForStatus(instance.ID, InstanceStatusActive)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Status).To(Equal(InstanceStatusActive))
+
+	got, err = lifecycle.Stop(instance.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Status).To(Equal(InstanceStatusShutoff))
+
+	_, err = lifecycle.Reboot(instance.ID)
+	transition := &InstanceTransitionError{}
+	g.Expect(errors.As(err, &transition)).To(BeTrue())
+	g.Expect(transition.Action).To(Equal(InstanceActionReboot))
+	g.Expect(transition.Status).To(Equal(InstanceStatusShutoff))
+
+	got, err = lifecycle.Start(instance.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Status).To(Equal(InstanceStatusActive))
+}
+
+func TestInstanceLifecycleResize(t *testing.T) {
+	g := NewWithT(t)
+
+	client, lifecycle := newLifecycleForTesting()
+	instance, _ := client.CreateInstance(&InstanceConfig{Hostname: "web", Size: "g3.small"})
+	lifecycle.WaitForStatus(instance.ID, InstanceStatusActive)
+
+	// Upgrading on its own needs the instance to be stopped
+	_, err := lifecycle.Upgrade(instance.ID, "g3.medium")
+	g.Expect(errors.Is(err, InstanceInvalidTransitionError)).To(BeTrue())
+
+	_, err = lifecycle.Resize(instance.ID, "g3.xsmall")
+	g.Expect(errors.Is(err, InstanceInvalidSizeError)).To(BeTrue())
+	_, err = lifecycle.Resize(instance.ID, "g3.small")
+	g.Expect(errors.Is(err, InstanceInvalidSizeError)).To(BeTrue())
+	_, err = lifecycle.Resize(instance.ID, "g9.unknown")
+	g.Expect(errors.Is(err, InstanceInvalidSizeError)).To(BeTrue())
+
+	got, err := client.GetInstance(instance.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Status).To(Equal(InstanceStatusActive))
+
+	got, err = lifecycle.Resize(instance.ID, "g3.medium")
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Size).To(Equal("g3.medium"))
+	g.Expect(got.Status).To(Equal(InstanceStatusActive))
+}
+
+// failingUpgradeClient fails every UpgradeInstance call
+type failingUpgradeClient struct {
+	*FakeClient
+}
+
+func (c failingUpgradeClient) UpgradeInstance(id, newSize string) (*SimpleResponse, error) {
+	return nil, errors.New("no capacity for " + newSize)
+}
+
+func TestInstanceLifecycleResizeRestartsAfterFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	client, lifecycle := newLifecycleForTesting()
+	instance, _ := client.CreateInstance(&InstanceConfig{Hostname: "web", Size: "g3.small"})
+	lifecycle.WaitForStatus(instance.ID, InstanceStatusActive)
+
+	lifecycle.Client = failingUpgradeClient{client}
+	_, err := lifecycle.Resize(instance.ID, "g3.medium")
+	g.Expect(err).To(MatchError("no capacity for g3.medium"))
+
+	got, _ := client.GetInstance(instance.ID)
+	g.Expect(got.Size).To(Equal("g3.small"))
+	g.Expect(got.Status).To(Equal(InstanceStatusActive))
+}
+
+func TestInstanceLifecycleTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	client, lifecycle := newLifecycleForTesting()
+	lifecycle.Timeout = 10 * time.Second
+	instance, _ := client.CreateInstance(&InstanceConfig{Hostname: "web"})
+
+	got, err := lifecycle.WaitForStatus(instance.ID, InstanceStatusActive)
+	g.Expect(errors.Is(err, TimeoutError)).To(BeTrue())
+	g.Expect(got.Status).To(Equal(InstanceStatusBuilding))
+
+	// An InstanceLifecycle without any timings set uses the defaults
+	lifecycle = &InstanceLifecycle{Client: client}
+	got, err = lifecycle.WaitForStatus(instance.ID, InstanceStatusBuilding)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Status).To(Equal(InstanceStatusBuilding))
+}
@@ -0,0 +1,167 @@
+package civogo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InstanceMetricsSample is a single civostatsd reading for an instance. The API
+// doesn't document the keys in a reading, so its numeric values are kept by key.
+type InstanceMetricsSample struct {
+	// Time is the reading's "time", in Unix seconds, and zero if it has none
+	Time   time.Time
+	Values map[string]float64
+}
+
+// InstanceMetrics is the civostatsd data for an instance, decoded from
+// Instance.CivostatsdStats, CivostatsdStatsPerMinute and CivostatsdStatsPerHour
+type InstanceMetrics struct {
+	InstanceID string
+	Hostname   string
+	Current    *InstanceMetricsSample
+	PerMinute  []InstanceMetricsSample
+	PerHour    []InstanceMetricsSample
+}
+
+// ParseInstanceMetricsSample decodes a single civostatsd reading, a JSON object
+// such as {"time": 1704110400, "load": 0.5}. The time must be whole Unix
+// seconds, other values which aren't numbers are skipped.
+func ParseInstanceMetricsSample(raw string) (*InstanceMetricsSample, error) {
+	values := map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, CivoStatsdStatsInvalidError.wrap(err)
+	}
+
+	sample := &InstanceMetricsSample{Values: map[string]float64{}}
+	for key, value := range values {
+		number, ok := value.(json.Number)
+		if key == "time" {
+			seconds, err := number.Int64()
+			if !ok || err != nil {
+				err := fmt.Errorf("unable to parse time %v, it must be whole Unix seconds", value)
+				return nil, CivoStatsdStatsInvalidError.wrap(err)
+			}
+			sample.Time = time.Unix(seconds, 0).UTC()
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if float, err := number.Float64(); err == nil {
+			sample.Values[key] = float
+		}
+	}
+
+	return sample, nil
+}
+
+// Metrics decodes the instance's civostatsd readings, each time series is sorted oldest first
+func (i *Instance) Metrics() (*InstanceMetrics, error) {
+	metrics := &InstanceMetrics{InstanceID: i.ID, Hostname: i.Hostname}
+
+	if strings.TrimSpace(i.CivostatsdStats) != "" {
+		sample, err := ParseInstanceMetricsSample(i.CivostatsdStats)
+		if err != nil {
+			return nil, err
+		}
+		metrics.Current = sample
+	}
+
+	var err error
+	if metrics.PerMinute, err = parseInstanceMetricsSeries(i.CivostatsdStatsPerMinute); err != nil {
+		return nil, err
+	}
+	if metrics.PerHour, err = parseInstanceMetricsSeries(i.CivostatsdStatsPerHour); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+func parseInstanceMetricsSeries(raw []string) ([]InstanceMetricsSample, error) {
+	series := []InstanceMetricsSample{}
+	for _, reading := range raw {
+		if strings.TrimSpace(reading) == "" {
+			continue
+		}
+		sample, err := ParseInstanceMetricsSample(reading)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, *sample)
+	}
+
+	sort.SliceStable(series, func(a, b int) bool { return series[a].Time.Before(series[b].Time) })
+	return series, nil
+}
+
+// GetInstanceMetrics returns the decoded civostatsd readings for an instance
+func (c *Client) GetInstanceMetrics(id string) (*InstanceMetrics, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	return instance.Metrics()
+}
+
+// Latest returns the current reading, or the newest per minute or per hour reading
+// if there isn't one
+func (m *InstanceMetrics) Latest() *InstanceMetricsSample {
+	if m.Current != nil {
+		return m.Current
+	}
+	if len(m.PerMinute) > 0 {
+		return &m.PerMinute[len(m.PerMinute)-1]
+	}
+	if len(m.PerHour) > 0 {
+		return &m.PerHour[len(m.PerHour)-1]
+	}
+	return nil
+}
+
+// prometheusMetricNamePattern matches the characters which aren't allowed in a
+// Prometheus metric name
+var prometheusMetricNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// WritePrometheus writes the latest reading in the Prometheus text exposition
+// format, a gauge per value named civo_instance_<key> and labelled with the
+// instance's ID and hostname. Nothing is written if there are no readings.
+func (m *InstanceMetrics) WritePrometheus(w io.Writer) error {
+	sample := m.Latest()
+	if sample == nil {
+		return nil
+	}
+
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace
+	labels := fmt.Sprintf(`{instance_id="%s",hostname="%s"}`, escape(m.InstanceID), escape(m.Hostname))
+	timestamp := ""
+	if !sample.Time.IsZero() {
+		timestamp = " " + strconv.FormatInt(sample.Time.UnixMilli(), 10)
+	}
+
+	keys := []string{}
+	for key := range sample.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := "civo_instance_" + prometheusMetricNamePattern.ReplaceAllString(key, "_")
+		_, err := fmt.Fprintf(w, "# HELP %s The civostatsd %q value for the instance.\n# TYPE %s gauge\n%s%s %s%s\n",
+			name, key, name, name, labels, strconv.FormatFloat(sample.Values[key], 'g', -1, 64), timestamp)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package civogo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseInstanceMetricsSample(t *testing.T) {
+	g := NewWithT(t)
+
+	sample, err := ParseInstanceMetricsSample(`{"time": 1704110400, "load": 0.5, "memory": 524288000}`)
+	g.Expect(err).To(BeNil())
+	g.Expect(sample.Time).To(Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)))
+	g.Expect(sample.Values).To(Equal(map[string]float64{"load": 0.5, "memory": 524288000}))
+
+	// Values which aren't numbers are skipped rather than failing the reading
+	sample, err = ParseInstanceMetricsSample(`{"load": 1, "status": "ok", "disk": null}`)
+	g.Expect(err).To(BeNil())
+	g.Expect(sample.Time.IsZero()).To(BeTrue())
+	g.Expect(sample.Values).To(Equal(map[string]float64{"load": 1}))
+
+	for _, raw := range []string{"load=0.5", `{"time": "yesterday"}`, `{"time": 1704110400.5}`, `{"time": 1.5e20}`} {
+		_, err = ParseInstanceMetricsSample(raw)
+		g.Expect(errors.Is(err, CivoStatsdStatsInvalidError)).To(BeTrue(), raw)
+	}
+}
+
+func TestGetInstanceMetrics(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.Instances = []Instance{{
+		ID:              "12345",
+		Hostname:        "web",
+		CivostatsdStats: `{"time": 1704110520, "load": 30}`,
+		CivostatsdStatsPerMinute: []string{
+			`{"time": 1704110460, "load": 20, "status": "ok"}`,
+			`{"time": 1704110400, "load": 10}`,
+		},
+	}}
+
+	metrics, err := client.GetInstanceMetrics("12345")
+	g.Expect(err).To(BeNil())
+	g.Expect(metrics.PerMinute).To(HaveLen(2))
+	g.Expect(metrics.PerMinute[0].Values["load"]).To(Equal(10.0))
+	g.Expect(metrics.PerHour).To(BeEmpty())
+	g.Expect(metrics.Latest().Values["load"]).To(Equal(30.0))
+
+	out := bytes.Buffer{}
+	g.Expect(metrics.WritePrometheus(&out)).To(Succeed())
+	g.Expect(out.String()).To(Equal("# HELP civo_instance_load The civostatsd \"load\" value for the instance.\n" +
+		"# TYPE civo_instance_load gauge\n" +
+		`civo_instance_load{instance_id="12345",hostname="web"} 30 1704110520000` + "\n"))
+
+	_, err = client.GetInstanceMetrics("missing")
+	g.Expect(errors.Is(err, DatabaseInstanceNotFoundError)).To(BeTrue())
+}
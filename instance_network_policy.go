@@ -0,0 +1,340 @@
+package civogo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NormalizeAllowedIP checks an allowed IP is an IPv4 or IPv6 address or CIDR
+// range and returns it in its canonical form, such as "2001:db8::/32"
+func NormalizeAllowedIP(ip string) (string, error) {
+	ip = strings.TrimSpace(ip)
+	if strings.Contains(ip, "/") {
+		prefix, err := netip.ParsePrefix(ip)
+		if err != nil {
+			err := fmt.Errorf("%q isn't a valid CIDR range", ip)
+			return "", ParameterAllowedIPInvalidError.wrap(err)
+		}
+		if prefix.Masked() != prefix {
+			err := fmt.Errorf("%q has host bits set, did you mean %s?", ip, prefix.Masked())
+			return "", ParameterAllowedIPInvalidError.wrap(err)
+		}
+		return prefix.String(), nil
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || addr.Zone() != "" {
+		err := fmt.Errorf("%q isn't a valid IP address", ip)
+		return "", ParameterAllowedIPInvalidError.wrap(err)
+	}
+	return addr.String(), nil
+}
+
+// NormalizeAllowedIPs normalizes each allowed IP, dropping duplicates
+func NormalizeAllowedIPs(ips []string) ([]string, error) {
+	result := []string{}
+	for _, ip := range ips {
+		normalized, err := NormalizeAllowedIP(ip)
+		if err != nil {
+			return nil, err
+		}
+		if !findString(result, normalized) {
+			result = append(result, normalized)
+		}
+	}
+	return result, nil
+}
+
+// ValidateAllowedIPs returns ParameterAllowedIPInvalidError if any of ips isn't
+// an IPv4 or IPv6 address or CIDR range
+func ValidateAllowedIPs(ips []string) error {
+	_, err := NormalizeAllowedIPs(ips)
+	return err
+}
+
+// AddInstanceAllowedIPs adds ips to the instance's allowed IPs, keeping the ones
+// it already has. Nothing is sent if they're all already allowed.
+func (c *Client) AddInstanceAllowedIPs(id string, ips []string) (*SimpleResponse, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	return updateInstanceAllowedIPs(c, instance, func(current []string) ([]string, error) {
+		return addAllowedIPs(current, ips)
+	})
+}
+
+// RemoveInstanceAllowedIPs removes ips from the instance's allowed IPs. Nothing
+// is sent if none of them are allowed.
+func (c *Client) RemoveInstanceAllowedIPs(id string, ips []string) (*SimpleResponse, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	return updateInstanceAllowedIPs(c, instance, func(current []string) ([]string, error) {
+		return removeAllowedIPs(current, ips)
+	})
+}
+
+func updateInstanceAllowedIPs(client Clienter, instance *Instance, change func([]string) ([]string, error)) (*SimpleResponse, error) {
+	updated, err := change(instance.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+	if sameAllowedIPs(instance.AllowedIPs, updated) {
+		return &SimpleResponse{ID: instance.ID, Result: ResultSuccess}, nil
+	}
+	return client.UpdateInstanceAllowedIPs(instance.ID, updated)
+}
+
+func addAllowedIPs(current, ips []string) ([]string, error) {
+	added, err := NormalizeAllowedIPs(ips)
+	if err != nil {
+		return nil, err
+	}
+
+	result := append([]string{}, current...)
+	for _, ip := range added {
+		if !containsAllowedIP(result, ip) {
+			result = append(result, ip)
+		}
+	}
+	return result, nil
+}
+
+func removeAllowedIPs(current, ips []string) ([]string, error) {
+	removed, err := NormalizeAllowedIPs(ips)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for _, ip := range current {
+		if !containsAllowedIP(removed, ip) {
+			result = append(result, ip)
+		}
+	}
+	return result, nil
+}
+
+// containsAllowedIP compares normalized forms, so "2001:DB8::1" matches "2001:db8::1"
+func containsAllowedIP(ips []string, search string) bool {
+	search = normalizeAllowedIPOrKeep(search)
+	for _, ip := range ips {
+		if normalizeAllowedIPOrKeep(ip) == search {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeAllowedIPOrKeep(ip string) string {
+	if normalized, err := NormalizeAllowedIP(ip); err == nil {
+		return normalized
+	}
+	return ip
+}
+
+// sameAllowedIPs returns true if a and b allow the same IPs, ignoring order and formatting
+func sameAllowedIPs(a, b []string) bool {
+	normalize := func(ips []string) []string {
+		result := []string{}
+		for _, ip := range ips {
+			if ip = normalizeAllowedIPOrKeep(ip); !findString(result, ip) {
+				result = append(result, ip)
+			}
+		}
+		sort.Strings(result)
+		return result
+	}
+	return strings.Join(normalize(a), ",") == strings.Join(normalize(b), ",")
+}
+
+var bandwidthPattern = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z/]*)\s*$`)
+
+// bandwidthUnits maps the bit rate units ParseBandwidth accepts to Mbps
+var bandwidthUnits = map[string]float64{
+	"": 1, "m": 1, "mbps": 1, "mbit": 1, "mbit/s": 1,
+	"g": 1000, "gbps": 1000, "gbit": 1000, "gbit/s": 1000,
+}
+
+// ParseBandwidth parses a bandwidth limit such as "500Mbps", "1.5 Gbps" or "100"
+// (which is taken as Mbps) and returns it in Mbps, the unit
+// Instance.NetworkBandwidthLimit and UpdateInstanceBandwidth use. Byte units
+// such as "500MB" are rejected rather than mistaken for bits.
+func ParseBandwidth(bandwidth string) (int, error) {
+	match := bandwidthPattern.FindStringSubmatch(bandwidth)
+	if match == nil {
+		err := fmt.Errorf("unable to parse bandwidth %q, expected a number of Mbps or Gbps such as \"500Mbps\"", bandwidth)
+		return 0, ParameterBandwidthInvalidError.wrap(err)
+	}
+
+	unit := strings.ToLower(match[2])
+	multiplier, ok := bandwidthUnits[unit]
+	if !ok {
+		err := fmt.Errorf("unable to parse bandwidth %q, %s isn't a unit of Mbps or Gbps", bandwidth, match[2])
+		if strings.HasPrefix(unit, "mb") || strings.HasPrefix(unit, "gb") {
+			err = fmt.Errorf("unable to parse bandwidth %q, %s is bytes rather than bits, use Mbps or Gbps such as \"500Mbps\"", bandwidth, match[2])
+		}
+		return 0, ParameterBandwidthInvalidError.wrap(err)
+	}
+
+	value, _ := strconv.ParseFloat(match[1], 64)
+	value *= multiplier
+	if value > math.MaxInt32 {
+		err := fmt.Errorf("bandwidth %q is too large", bandwidth)
+		return 0, ParameterBandwidthInvalidError.wrap(err)
+	}
+	if value != math.Trunc(value) {
+		err := fmt.Errorf("bandwidth %q must be a whole number of Mbps", bandwidth)
+		return 0, ParameterBandwidthInvalidError.wrap(err)
+	}
+	if err := validateBandwidthLimit(int(value)); err != nil {
+		return 0, err
+	}
+	return int(value), nil
+}
+
+// validateBandwidthLimit checks a limit in Mbps is at least 1Mbps
+func validateBandwidthLimit(mbps int) error {
+	if mbps < 1 {
+		err := fmt.Errorf("bandwidth limit %dMbps must be at least 1Mbps", mbps)
+		return ParameterBandwidthInvalidError.wrap(err)
+	}
+	return nil
+}
+
+// FormatBandwidth returns a limit in Mbps as a string ParseBandwidth accepts,
+// using Gbps for whole numbers of them
+func FormatBandwidth(mbps int) string {
+	if mbps >= 1000 && mbps%1000 == 0 {
+		return fmt.Sprintf("%dGbps", mbps/1000)
+	}
+	return fmt.Sprintf("%dMbps", mbps)
+}
+
+// InstanceNetworkPolicy is an allowed IPs and bandwidth limit to apply to every
+// instance with a tag
+type InstanceNetworkPolicy struct {
+	// Tag selects the instances, matching as ListInstancesByTag does
+	Tag string
+	// AllowedIPs replaces each instance's allowed IPs, nil leaves them alone
+	// while an empty slice clears them
+	AllowedIPs []string
+	// Bandwidth is the limit such as "1Gbps", empty leaves it alone
+	Bandwidth string
+}
+
+// InstanceNetworkPolicyChange is what applying a policy changes on one instance
+type InstanceNetworkPolicyChange struct {
+	InstanceID        string
+	Hostname          string
+	AllowedIPs        []string
+	CurrentAllowedIPs []string
+	// BandwidthLimit and CurrentBandwidthLimit are in Mbps
+	BandwidthLimit        int
+	CurrentBandwidthLimit int
+	UpdateAllowedIPs      bool
+	UpdateBandwidth       bool
+}
+
+// Validate checks the policy's tag, allowed IPs and bandwidth
+func (p *InstanceNetworkPolicy) Validate() error {
+	if err := validateInstanceTags([]string{p.Tag}); err != nil {
+		return err
+	}
+	if err := ValidateAllowedIPs(p.AllowedIPs); err != nil {
+		return err
+	}
+	if p.Bandwidth != "" {
+		if _, err := ParseBandwidth(p.Bandwidth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plan returns the changes applying the policy would make, skipping instances
+// which already match it
+func (p *InstanceNetworkPolicy) Plan(client Clienter) ([]InstanceNetworkPolicyChange, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	allowedIPs, _ := NormalizeAllowedIPs(p.AllowedIPs)
+	bandwidth := 0
+	if p.Bandwidth != "" {
+		bandwidth, _ = ParseBandwidth(p.Bandwidth)
+	}
+
+	instances, err := client.ListInstancesByTag(p.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []InstanceNetworkPolicyChange{}
+	for _, instance := range instances {
+		change := InstanceNetworkPolicyChange{
+			InstanceID:            instance.ID,
+			Hostname:              instance.Hostname,
+			AllowedIPs:            instance.AllowedIPs,
+			CurrentAllowedIPs:     instance.AllowedIPs,
+			BandwidthLimit:        instance.NetworkBandwidthLimit,
+			CurrentBandwidthLimit: instance.NetworkBandwidthLimit,
+		}
+		if p.AllowedIPs != nil && !sameAllowedIPs(instance.AllowedIPs, allowedIPs) {
+			change.AllowedIPs = allowedIPs
+			change.UpdateAllowedIPs = true
+		}
+		if bandwidth != 0 && instance.NetworkBandwidthLimit != bandwidth {
+			change.BandwidthLimit = bandwidth
+			change.UpdateBandwidth = true
+		}
+		if change.UpdateAllowedIPs || change.UpdateBandwidth {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// Apply makes the planned changes, carrying on past instances which fail and
+// returning the changes that were made along with every error. If only the
+// bandwidth update fails, the change is returned with just UpdateAllowedIPs set.
+func (p *InstanceNetworkPolicy) Apply(client Clienter) ([]InstanceNetworkPolicyChange, error) {
+	changes, err := p.Plan(client)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []InstanceNetworkPolicyChange{}
+	errs := []error{}
+	for _, change := range changes {
+		if change.UpdateAllowedIPs {
+			if _, err := client.UpdateInstanceAllowedIPs(change.InstanceID, change.AllowedIPs); err != nil {
+				errs = append(errs, fmt.Errorf("instance %s: %w", change.InstanceID, err))
+				continue
+			}
+		}
+		if change.UpdateBandwidth {
+			if _, err := client.UpdateInstanceBandwidth(change.InstanceID, change.BandwidthLimit); err != nil {
+				errs = append(errs, fmt.Errorf("instance %s: %w", change.InstanceID, err))
+				if !change.UpdateAllowedIPs {
+					continue
+				}
+				// The allowed IPs were still changed
+				change.BandwidthLimit = change.CurrentBandwidthLimit
+				change.UpdateBandwidth = false
+			}
+		}
+		applied = append(applied, change)
+	}
+	return applied, errors.Join(errs...)
+}
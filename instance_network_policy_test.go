@@ -0,0 +1,185 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNormalizeAllowedIP(t *testing.T) {
+	g := NewWithT(t)
+
+	valid := map[string]string{
+		"192.168.1.10":       "192.168.1.10",
+		" 10.0.0.0/8 ":       "10.0.0.0/8",
+		"2001:DB8::1":        "2001:db8::1",
+		"2001:db8:0::/32":    "2001:db8::/32",
+		"0.0.0.0/0":          "0.0.0.0/0",
+		"::ffff:192.0.2.128": "::ffff:192.0.2.128",
+	}
+	for ip, expected := range valid {
+		got, err := NormalizeAllowedIP(ip)
+		g.Expect(err).To(BeNil(), ip)
+		g.Expect(got).To(Equal(expected), ip)
+	}
+
+	for _, ip := range []string{"", "300.1.1.1", "10.0.0.0/33", "10.0.0.5/24", "fe80::1%eth0", "example.com"} {
+		_, err := NormalizeAllowedIP(ip)
+		g.Expect(errors.Is(err, ParameterAllowedIPInvalidError)).To(BeTrue(), ip)
+	}
+
+	err := ValidateAllowedIPs([]string{"10.0.0.1", "nope"})
+	g.Expect(err).To(MatchError(`ParameterAllowedIPInvalidError: "nope" isn't a valid IP address`))
+}
+
+func TestParseBandwidth(t *testing.T) {
+	g := NewWithT(t)
+
+	valid := map[string]int{
+		"100":      100,
+		"500Mbps":  500,
+		"500 mbps": 500,
+		"1Gbps":    1000,
+		"1.5 Gbps": 1500,
+		"2G":       2000,
+		"10mbit/s": 10,
+		"1 gbit":   1000,
+		"250M":     250,
+	}
+	for bandwidth, expected := range valid {
+		got, err := ParseBandwidth(bandwidth)
+		g.Expect(err).To(BeNil(), bandwidth)
+		g.Expect(got).To(Equal(expected), bandwidth)
+	}
+
+	for _, bandwidth := range []string{"", "fast", "0", "1.5Mbps", "10Tbps", "-5", "100000000000000000000", "3000000G"} {
+		_, err := ParseBandwidth(bandwidth)
+		g.Expect(errors.Is(err, ParameterBandwidthInvalidError)).To(BeTrue(), bandwidth)
+	}
+	_, err := ParseBandwidth("500MB")
+	g.Expect(err).To(MatchError(`ParameterBandwidthInvalidError: unable to parse bandwidth "500MB", MB is bytes rather than bits, use Mbps or Gbps such as "500Mbps"`))
+	_, err = ParseBandwidth("1GB/s")
+	g.Expect(errors.Is(err, ParameterBandwidthInvalidError)).To(BeTrue())
+	_, err = ParseBandwidth("100000000000000000000")
+	g.Expect(err).To(MatchError(`ParameterBandwidthInvalidError: bandwidth "100000000000000000000" is too large`))
+
+	g.Expect(FormatBandwidth(2000)).To(Equal("2Gbps"))
+	g.Expect(FormatBandwidth(1500)).To(Equal("1500Mbps"))
+}
+
+func TestAddRemoveInstanceAllowedIPs(t *testing.T) {
+	g := NewWithT(t)
+
+	fake, _ := NewFakeClient()
+	instance, _ := fake.CreateInstance(&InstanceConfig{Hostname: "web"})
+	fake.UpdateInstanceAllowedIPs(instance.ID, []string{"10.0.0.1"})
+
+	server := newFakeAPIServer(t, fake)
+	client, _ := NewClientForTestingWithServer(server)
+
+	_, err := client.AddInstanceAllowedIPs(instance.ID, []string{"10.0.0.1", "2001:DB8::/32"})
+	g.Expect(err).To(BeNil())
+	got, _ := fake.GetInstance(instance.ID)
+	g.Expect(got.AllowedIPs).To(Equal([]string{"10.0.0.1", "2001:db8::/32"}))
+
+	_, err = client.RemoveInstanceAllowedIPs(instance.ID, []string{"2001:db8:0::/32"})
+	g.Expect(err).To(BeNil())
+	got, _ = fake.GetInstance(instance.ID)
+	g.Expect(got.AllowedIPs).To(Equal([]string{"10.0.0.1"}))
+
+	_, err = client.AddInstanceAllowedIPs(instance.ID, []string{"10.0.0.1/24"})
+	g.Expect(errors.Is(err, ParameterAllowedIPInvalidError)).To(BeTrue())
+
+	// Setting the allowed IPs and bandwidth directly is validated before anything is sent
+	_, err = client.UpdateInstanceAllowedIPs(instance.ID, []string{"10.0.0.1", "not-an-ip"})
+	g.Expect(errors.Is(err, ParameterAllowedIPInvalidError)).To(BeTrue())
+	_, err = client.UpdateInstanceBandwidth(instance.ID, 0)
+	g.Expect(err).To(MatchError("ParameterBandwidthInvalidError: bandwidth limit 0Mbps must be at least 1Mbps"))
+	got, _ = fake.GetInstance(instance.ID)
+	g.Expect(got.AllowedIPs).To(Equal([]string{"10.0.0.1"}))
+}
+
+func TestInstanceNetworkPolicyApply(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	web1, _ := client.CreateInstance(&InstanceConfig{Hostname: "web1", Tags: []string{"role=web"}})
+	web2, _ := client.CreateInstance(&InstanceConfig{Hostname: "web2", Tags: []string{"role=web"}})
+	db, _ := client.CreateInstance(&InstanceConfig{Hostname: "db", Tags: []string{"role=db"}})
+	client.UpdateInstanceAllowedIPs(web2.ID, []string{"192.168.0.0/16", "10.0.0.0/8"})
+	client.UpdateInstanceBandwidth(web2.ID, 1000)
+
+	policy := &InstanceNetworkPolicy{Tag: "role=web", AllowedIPs: []string{"10.0.0.0/8", "192.168.0.0/16"}, Bandwidth: "1Gbps"}
+	changes, err := policy.Plan(client)
+	g.Expect(err).To(BeNil())
+	g.Expect(changes).To(Equal([]InstanceNetworkPolicyChange{{
+		InstanceID:       web1.ID,
+		Hostname:         "web1",
+		AllowedIPs:       []string{"10.0.0.0/8", "192.168.0.0/16"},
+		BandwidthLimit:   1000,
+		UpdateAllowedIPs: true,
+		UpdateBandwidth:  true,
+	}}))
+
+	applied, err := policy.Apply(client)
+	g.Expect(err).To(BeNil())
+	g.Expect(applied).To(Equal(changes))
+	got, _ := client.GetInstance(web1.ID)
+	g.Expect(got.AllowedIPs).To(Equal([]string{"10.0.0.0/8", "192.168.0.0/16"}))
+	g.Expect(got.NetworkBandwidthLimit).To(Equal(1000))
+	got, _ = client.GetInstance(db.ID)
+	g.Expect(got.AllowedIPs).To(BeEmpty())
+
+	// Applying again changes nothing
+	changes, err = policy.Plan(client)
+	g.Expect(err).To(BeNil())
+	g.Expect(changes).To(BeEmpty())
+
+	// A nil AllowedIPs leaves them alone
+	policy = &InstanceNetworkPolicy{Tag: "role", Bandwidth: "500Mbps"}
+	applied, err = policy.Apply(client)
+	g.Expect(err).To(BeNil())
+	g.Expect(applied).To(HaveLen(3))
+	got, _ = client.GetInstance(web1.ID)
+	g.Expect(got.AllowedIPs).To(HaveLen(2))
+	g.Expect(got.NetworkBandwidthLimit).To(Equal(500))
+
+	policy = &InstanceNetworkPolicy{Tag: "role=web", Bandwidth: "lots"}
+	_, err = policy.Apply(client)
+	g.Expect(errors.Is(err, ParameterBandwidthInvalidError)).To(BeTrue())
+}
+
+// failingBandwidthClient fails every UpdateInstanceBandwidth call
+type failingBandwidthClient struct {
+	*FakeClient
+}
+
+func (c failingBandwidthClient) UpdateInstanceBandwidth(id string, bandwidthLimit int) (*SimpleResponse, error) {
+	return nil, errors.New("bandwidth service unavailable")
+}
+
+func TestInstanceNetworkPolicyApplyPartial(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	web, _ := client.CreateInstance(&InstanceConfig{Hostname: "web", Tags: []string{"role=web"}})
+	client.UpdateInstanceBandwidth(web.ID, 100)
+	other, _ := client.CreateInstance(&InstanceConfig{Hostname: "other", Tags: []string{"role=web"}})
+	client.UpdateInstanceAllowedIPs(other.ID, []string{"10.0.0.0/8"})
+
+	// The allowed IPs change is reported even though the bandwidth change failed
+	policy := &InstanceNetworkPolicy{Tag: "role=web", AllowedIPs: []string{"10.0.0.0/8"}, Bandwidth: "1Gbps"}
+	applied, err := policy.Apply(failingBandwidthClient{client})
+	g.Expect(err).To(MatchError("instance " + web.ID + ": bandwidth service unavailable\ninstance " + other.ID + ": bandwidth service unavailable"))
+	g.Expect(applied).To(Equal([]InstanceNetworkPolicyChange{{
+		InstanceID:            web.ID,
+		Hostname:              "web",
+		AllowedIPs:            []string{"10.0.0.0/8"},
+		BandwidthLimit:        100,
+		CurrentBandwidthLimit: 100,
+		UpdateAllowedIPs:      true,
+	}}))
+	got, _ := client.GetInstance(web.ID)
+	g.Expect(got.AllowedIPs).To(Equal([]string{"10.0.0.0/8"}))
+}
@@ -0,0 +1,349 @@
+package civogo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/civo/civogo/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// InstanceProfile is a named, reusable preset for InstanceConfig. Sizes, images,
+// networks and SSH keys are given by name or ID and looked up when the profile
+// is turned into a config. Any string may refer to variables as ${name}, while
+// $${name} is left as ${name}, for example for shell variables in Script.
+type InstanceProfile struct {
+	// Extends names the profile this one is based on, its non-empty fields override the base's
+	Extends     string `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Hostname    string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Size        string `json:"size,omitempty" yaml:"size,omitempty"`
+	Region      string `json:"region,omitempty" yaml:"region,omitempty"`
+	Image       string `json:"image,omitempty" yaml:"image,omitempty"`
+	Network     string `json:"network,omitempty" yaml:"network,omitempty"`
+	SSHKey      string `json:"ssh_key,omitempty" yaml:"ssh_key,omitempty"`
+	InitialUser string `json:"initial_user,omitempty" yaml:"initial_user,omitempty"`
+	FirewallID  string `json:"firewall_id,omitempty" yaml:"firewall_id,omitempty"`
+	// PublicIP is "true" or "false" as for InstanceConfig.PublicIPRequired
+	PublicIP   string `json:"public_ip,omitempty" yaml:"public_ip,omitempty"`
+	VolumeType string `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
+	Script     string `json:"script,omitempty" yaml:"script,omitempty"`
+	// Tags replaces the base profile's tags when set
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Variables are the defaults for variables, merged with the base profile's
+	Variables map[string]string `json:"variables,omitempty" yaml:"variables,omitempty"`
+}
+
+// InstanceProfiles is a set of named profiles, as stored in a YAML or JSON file
+// with the profiles under a "profiles" key
+type InstanceProfiles struct {
+	Profiles map[string]*InstanceProfile `json:"profiles" yaml:"profiles"`
+}
+
+// ParseInstanceProfiles decodes profiles from JSON or YAML
+func ParseInstanceProfiles(data []byte) (*InstanceProfiles, error) {
+	profiles := &InstanceProfiles{}
+	var err error
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		err = json.Unmarshal(data, profiles)
+	} else {
+		err = yaml.UnmarshalStrict(data, profiles)
+	}
+	if err != nil {
+		return nil, InstanceProfileInvalidError.wrap(err)
+	}
+	if profiles.Profiles == nil {
+		profiles.Profiles = map[string]*InstanceProfile{}
+	}
+	return profiles, nil
+}
+
+// LoadInstanceProfiles reads profiles from a YAML or JSON file
+func LoadInstanceProfiles(path string) (*InstanceProfiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInstanceProfiles(data)
+}
+
+// Save writes the profiles to path, as JSON if it ends in ".json" and YAML otherwise
+func (p *InstanceProfiles) Save(path string) error {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(p, "", "  ")
+	} else {
+		data, err = yaml.Marshal(p)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Set adds or replaces the named profile
+func (p *InstanceProfiles) Set(name string, profile *InstanceProfile) {
+	if p.Profiles == nil {
+		p.Profiles = map[string]*InstanceProfile{}
+	}
+	p.Profiles[name] = profile
+}
+
+// Names returns the profile names in order
+func (p *InstanceProfiles) Names() []string {
+	names := []string{}
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the named profile with the profiles it extends merged in and
+// its variables substituted. vars override the profiles' variable defaults, a
+// variable without a value is an error.
+func (p *InstanceProfiles) Resolve(name string, vars map[string]string) (*InstanceProfile, error) {
+	chain := []*InstanceProfile{}
+	seen := map[string]bool{}
+	for current := name; current != ""; {
+		if seen[current] {
+			err := fmt.Errorf("profile %s extends itself", current)
+			return nil, InstanceProfileInvalidError.wrap(err)
+		}
+		seen[current] = true
+
+		profile, ok := p.Profiles[current]
+		if !ok || profile == nil {
+			err := fmt.Errorf("unable to find profile %s", current)
+			return nil, InstanceProfileNotFoundError.wrap(err)
+		}
+		chain = append([]*InstanceProfile{profile}, chain...)
+		current = profile.Extends
+	}
+
+	merged := &InstanceProfile{}
+	for _, profile := range chain {
+		merged.merge(profile)
+	}
+	merged.Extends = ""
+
+	values := map[string]string{}
+	for key, value := range merged.Variables {
+		values[key] = value
+	}
+	for key, value := range vars {
+		values[key] = value
+	}
+	if err := merged.substitute(values); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// Config resolves the named profile and turns it into an InstanceConfig, see InstanceProfileConfig
+func (p *InstanceProfiles) Config(client Clienter, name string, vars map[string]string) (*InstanceConfig, error) {
+	profile, err := p.Resolve(name, vars)
+	if err != nil {
+		return nil, err
+	}
+	return InstanceProfileConfig(client, profile)
+}
+
+// CreateInstance creates an instance from the named profile, after checking
+// everything it refers to exists
+func (p *InstanceProfiles) CreateInstance(client Clienter, name string, vars map[string]string) (*Instance, error) {
+	config, err := p.Config(client, name, vars)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateInstance(config)
+}
+
+// ValidateInstanceProfile returns InstanceProfileInvalidError listing every size,
+// image, network or SSH key the resolved profile refers to which doesn't exist
+func ValidateInstanceProfile(client Clienter, profile *InstanceProfile) error {
+	_, err := InstanceProfileConfig(client, profile)
+	return err
+}
+
+// InstanceProfileConfig looks up the sizes, images, networks and SSH keys a
+// resolved profile refers to and returns the InstanceConfig for it. The network
+// defaults to the default network, the initial user to the image's and the
+// hostname to a random name.
+func InstanceProfileConfig(client Clienter, profile *InstanceProfile) (*InstanceConfig, error) {
+	config := &InstanceConfig{
+		Count:            1,
+		Hostname:         profile.Hostname,
+		Region:           profile.Region,
+		PublicIPRequired: profile.PublicIP,
+		InitialUser:      profile.InitialUser,
+		FirewallID:       profile.FirewallID,
+		VolumeType:       profile.VolumeType,
+		Script:           profile.Script,
+		Tags:             profile.Tags,
+	}
+	if config.Hostname == "" {
+		config.Hostname = utils.RandomName()
+	}
+	if config.PublicIPRequired == "" {
+		config.PublicIPRequired = "true"
+	}
+
+	problems := []string{}
+	if profile.PublicIP != "" && profile.PublicIP != "true" && profile.PublicIP != "false" {
+		problems = append(problems, fmt.Sprintf("public_ip %q must be true or false", profile.PublicIP))
+	}
+	if err := validateInstanceTags(profile.Tags); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if profile.Size == "" {
+		problems = append(problems, "size is required")
+	} else {
+		sizes, err := client.ListInstanceSizes()
+		if err != nil {
+			return nil, err
+		}
+		_, err = findMatch(sizes, profile.Size, FindModeExact,
+			func(s InstanceSize) string { return s.Name }, func(s InstanceSize) []string { return []string{s.Name} })
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("size %s doesn't exist", profile.Size))
+		}
+		config.Size = profile.Size
+	}
+
+	if profile.Image == "" {
+		problems = append(problems, "image is required")
+	} else {
+		images, err := client.ListDiskImages()
+		if err != nil {
+			return nil, err
+		}
+		image, err := findMatch(images, profile.Image, FindModeExact,
+			func(i DiskImage) string { return i.ID }, func(i DiskImage) []string { return []string{i.Name, i.Label} })
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("image %s doesn't exist", profile.Image))
+		} else {
+			config.TemplateID = image.ID
+			if config.InitialUser == "" {
+				config.InitialUser = image.InitialUser
+			}
+		}
+	}
+	if config.InitialUser == "" {
+		config.InitialUser = DefaultInstanceUser
+	}
+
+	if profile.Network == "" {
+		network, err := client.GetDefaultNetwork()
+		if err != nil {
+			return nil, err
+		}
+		config.NetworkID = network.ID
+	} else {
+		networks, err := client.ListNetworks()
+		if err != nil {
+			return nil, err
+		}
+		network, err := findMatch(networks, profile.Network, FindModeExact,
+			func(n Network) string { return n.ID }, func(n Network) []string { return []string{n.Name, n.Label} })
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("network %s doesn't exist", profile.Network))
+		} else {
+			config.NetworkID = network.ID
+		}
+	}
+
+	if profile.SSHKey != "" {
+		keys, err := client.ListSSHKeys()
+		if err != nil {
+			return nil, err
+		}
+		key, err := findMatch(keys, profile.SSHKey, FindModeExact,
+			func(k SSHKey) string { return k.ID }, func(k SSHKey) []string { return []string{k.Name} })
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("SSH key %s doesn't exist", profile.SSHKey))
+		} else {
+			config.SSHKeyID = key.ID
+		}
+	}
+
+	if len(problems) > 0 {
+		err := fmt.Errorf("%s", strings.Join(problems, ", "))
+		return nil, InstanceProfileInvalidError.wrap(err)
+	}
+	return config, nil
+}
+
+// merge overrides the profile's fields with override's non-empty ones
+func (p *InstanceProfile) merge(override *InstanceProfile) {
+	fields := []struct{ to, from *string }{
+		{&p.Description, &override.Description},
+		{&p.Hostname, &override.Hostname},
+		{&p.Size, &override.Size},
+		{&p.Region, &override.Region},
+		{&p.Image, &override.Image},
+		{&p.Network, &override.Network},
+		{&p.SSHKey, &override.SSHKey},
+		{&p.InitialUser, &override.InitialUser},
+		{&p.FirewallID, &override.FirewallID},
+		{&p.PublicIP, &override.PublicIP},
+		{&p.VolumeType, &override.VolumeType},
+		{&p.Script, &override.Script},
+	}
+	for _, field := range fields {
+		if *field.from != "" {
+			*field.to = *field.from
+		}
+	}
+	if override.Tags != nil {
+		p.Tags = append([]string{}, override.Tags...)
+	}
+	for key, value := range override.Variables {
+		if p.Variables == nil {
+			p.Variables = map[string]string{}
+		}
+		p.Variables[key] = value
+	}
+}
+
+var instanceProfileVariable = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// substitute replaces ${name} in every string field with its value, and the
+// escaped $${name} with ${name}
+func (p *InstanceProfile) substitute(values map[string]string) error {
+	missing := []string{}
+	replace := func(s string) string {
+		return instanceProfileVariable.ReplaceAllStringFunc(s, func(match string) string {
+			if strings.HasPrefix(match, "$$") {
+				return match[1:]
+			}
+			name := instanceProfileVariable.FindStringSubmatch(match)[1]
+			value, ok := values[name]
+			if !ok && !findString(missing, name) {
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+
+	for _, field := range []*string{&p.Description, &p.Hostname, &p.Size, &p.Region, &p.Image, &p.Network,
+		&p.SSHKey, &p.InitialUser, &p.FirewallID, &p.PublicIP, &p.VolumeType, &p.Script} {
+		*field = replace(*field)
+	}
+	for i, tag := range p.Tags {
+		p.Tags[i] = replace(tag)
+	}
+
+	if len(missing) > 0 {
+		err := fmt.Errorf("no value for variables %s", strings.Join(missing, ", "))
+		return InstanceProfileInvalidError.wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package civogo
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const testInstanceProfiles = `
+profiles:
+  base:
+    size: g3.xsmall
+    image: ubuntu-20-4
+    tags: [managed]
+    variables:
+      env: dev
+  web:
+    extends: base
+    hostname: web-${env}-${n}
+    size: g3.small
+    network: ${env}-net
+    ssh_key: deploy
+    tags: [managed, "env=${env}", role=web]
+`
+
+func newInstanceProfileClient() *FakeClient {
+	client, _ := NewFakeClient()
+	client.Networks = []Network{
+		{ID: "net-1", Label: "default", Default: true},
+		{ID: "net-2", Label: "prod-net"},
+	}
+	client.SSHKeys = []SSHKey{{ID: "key-1", Name: "deploy"}}
+	return client
+}
+
+func TestInstanceProfilesResolve(t *testing.T) {
+	g := NewWithT(t)
+
+	profiles, err := ParseInstanceProfiles([]byte(testInstanceProfiles))
+	g.Expect(err).To(BeNil())
+	g.Expect(profiles.Names()).To(Equal([]string{"base", "web"}))
+
+	profile, err := profiles.Resolve("web", map[string]string{"env": "prod", "n": "1"})
+	g.Expect(err).To(BeNil())
+	g.Expect(profile).To(Equal(&InstanceProfile{
+		Hostname:  "web-prod-1",
+		Size:      "g3.small",
+		Image:     "ubuntu-20-4",
+		Network:   "prod-net",
+		SSHKey:    "deploy",
+		Tags:      []string{"managed", "env=prod", "role=web"},
+		Variables: map[string]string{"env": "dev"},
+	}))
+
+	// The stored profiles aren't changed by resolving them
+	g.Expect(profiles.Profiles["web"].Hostname).To(Equal("web-${env}-${n}"))
+
+	_, err = profiles.Resolve("web", nil)
+	g.Expect(err).To(MatchError("InstanceProfileInvalidError: no value for variables n"))
+
+	// Shell variables are escaped as $${name}, ${1} isn't a profile variable
+	profiles.Set("setup", &InstanceProfile{Extends: "base", Script: "#!/bin/sh\necho ${env} > $${HOME}/env\nexec app ${1}\n"})
+	profile, err = profiles.Resolve("setup", nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(profile.Script).To(Equal("#!/bin/sh\necho dev > ${HOME}/env\nexec app ${1}\n"))
+
+	_, err = profiles.Resolve("db", nil)
+	g.Expect(errors.Is(err, InstanceProfileNotFoundError)).To(BeTrue())
+
+	profiles.Set("loop-a", &InstanceProfile{Extends: "loop-b"})
+	profiles.Set("loop-b", &InstanceProfile{Extends: "loop-a"})
+	_, err = profiles.Resolve("loop-a", nil)
+	g.Expect(err).To(MatchError("InstanceProfileInvalidError: profile loop-a extends itself"))
+
+	_, err = ParseInstanceProfiles([]byte("profiles:\n  web:\n    sizes: g3.small\n"))
+	g.Expect(errors.Is(err, InstanceProfileInvalidError)).To(BeTrue())
+}
+
+func TestInstanceProfilesSaveLoad(t *testing.T) {
+	g := NewWithT(t)
+
+	profiles, _ := ParseInstanceProfiles([]byte(testInstanceProfiles))
+	for _, name := range []string{"profiles.yaml", "profiles.json"} {
+		path := filepath.Join(t.TempDir(), name)
+		g.Expect(profiles.Save(path)).To(Succeed())
+
+		loaded, err := LoadInstanceProfiles(path)
+		g.Expect(err).To(BeNil())
+		g.Expect(loaded).To(Equal(profiles), name)
+	}
+}
+
+func TestInstanceProfilesCreateInstance(t *testing.T) {
+	g := NewWithT(t)
+
+	client := newInstanceProfileClient()
+	profiles, _ := ParseInstanceProfiles([]byte(testInstanceProfiles))
+
+	config, err := profiles.Config(client, "base", nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(config.NetworkID).To(Equal("net-1"))
+	g.Expect(config.InitialUser).To(Equal(DefaultInstanceUser))
+	g.Expect(config.Hostname).NotTo(BeEmpty())
+
+	instance, err := profiles.CreateInstance(client, "web", map[string]string{"env": "prod", "n": "2"})
+	g.Expect(err).To(BeNil())
+	g.Expect(instance.Hostname).To(Equal("web-prod-2"))
+	g.Expect(instance.Size).To(Equal("g3.small"))
+	g.Expect(instance.TemplateID).To(Equal("b82168fe-66f6-4b38-a3b8-528354282548"))
+	g.Expect(instance.SSHKey).To(Equal("key-1"))
+	g.Expect(instance.Tags).To(Equal([]string{"managed", "env=prod", "role=web"}))
+
+	// Every missing reference is reported before anything is created
+	_, err = profiles.CreateInstance(client, "web", map[string]string{"n": "3"})
+	g.Expect(errors.Is(err, InstanceProfileInvalidError)).To(BeTrue())
+	g.Expect(err.Error()).To(Equal("InstanceProfileInvalidError: network dev-net doesn't exist"))
+	g.Expect(client.Instances).To(HaveLen(1))
+
+	err = ValidateInstanceProfile(client, &InstanceProfile{Size: "g9.huge", Image: "windows", SSHKey: "laptop", PublicIP: "yes"})
+	g.Expect(err).To(MatchError(`InstanceProfileInvalidError: public_ip "yes" must be true or false, size g9.huge doesn't exist, image windows doesn't exist, SSH key laptop doesn't exist`))
+
+	err = ValidateInstanceProfile(client, &InstanceProfile{})
+	g.Expect(err).To(MatchError("InstanceProfileInvalidError: size is required, image is required"))
+}
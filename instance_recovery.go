@@ -0,0 +1,76 @@
+package civogo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// InstanceRecoveryUser is the user to log in to an instance's rescue environment as
+const InstanceRecoveryUser = "root"
+
+// InstanceRecoveryCredentials are the details needed to log in to an instance's
+// rescue environment
+type InstanceRecoveryCredentials struct {
+	InstanceID string
+	Hostname   string
+	PublicIP   string
+	Username   string
+	Password   string
+}
+
+// Recover boots the instance into its rescue environment, waits for the API to
+// report its rescue password and calls work with the credentials for it.
+// Recovery mode is always disabled afterwards, whether work succeeds or not,
+// including when the password never appears. Once it's disabled Recover waits
+// for the instance to be ACTIVE without a rescue password, unless something
+// has already gone wrong.
+func (l *InstanceLifecycle) Recover(id string, work func(credentials *InstanceRecoveryCredentials) error) (err error) {
+	if work == nil {
+		err := fmt.Errorf("a function to run in the rescue environment is required")
+		return ParameterRecoveryWorkMissingError.wrap(err)
+	}
+
+	instance, err := l.Client.GetInstance(id)
+	if err != nil {
+		return err
+	}
+	if err := CheckInstanceTransition(instance, InstanceActionRecover); err != nil {
+		return err
+	}
+
+	if _, err := l.Client.EnableRecoveryMode(id); err != nil {
+		return err
+	}
+	defer func() {
+		if _, disableErr := l.Client.DisableRecoveryMode(id); disableErr != nil {
+			err = errors.Join(err, disableErr)
+			return
+		}
+		if err == nil {
+			_, err = l.waitFor(id, func(i *Instance) bool {
+				return i.Status == InstanceStatusActive && i.RescuePassword == ""
+			}, "ACTIVE without recovery mode")
+		}
+	}()
+
+	instance, err = l.waitFor(id, func(i *Instance) bool {
+		return i.RescuePassword != ""
+	}, "the rescue environment")
+	if err != nil {
+		return err
+	}
+
+	return work(&InstanceRecoveryCredentials{
+		InstanceID: instance.ID,
+		Hostname:   instance.Hostname,
+		PublicIP:   instance.PublicIP,
+		Username:   InstanceRecoveryUser,
+		Password:   instance.RescuePassword,
+	})
+}
+
+// RecoverInstance runs work against the instance's rescue environment using the
+// default timings, see InstanceLifecycle.Recover
+func (c *Client) RecoverInstance(id string, work func(credentials *InstanceRecoveryCredentials) error) error {
+	return NewInstanceLifecycle(c).Recover(id, work)
+}
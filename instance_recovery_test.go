@@ -0,0 +1,92 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRecoverInstanceAgainstFakeAPI(t *testing.T) {
+	g := NewWithT(t)
+
+	fake, _ := NewFakeClient()
+	fake.Clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	fake.ActionDuration = 30 * time.Second
+	instance, _ := fake.CreateInstance(&InstanceConfig{Hostname: "web", Size: "g3.xsmall"})
+
+	server := newFakeAPIServer(t, fake)
+	client, _ := NewClientForTestingWithServer(server)
+	lifecycle := NewInstanceLifecycle(client)
+	lifecycle.Sleep = fake.Clock.Sleep
+	lifecycle.Now = fake.Clock.Now
+
+	var got *InstanceRecoveryCredentials
+	err := lifecycle.Recover(instance.ID, func(credentials *InstanceRecoveryCredentials) error {
+		got = credentials
+		status, err := client.GetRecoveryStatus(instance.ID)
+		g.Expect(err).To(BeNil())
+		g.Expect(status.Result).To(Equal(Result(ResultSuccess)))
+		return nil
+	})
+	g.Expect(err).To(BeNil())
+	g.Expect(got).To(Equal(&InstanceRecoveryCredentials{
+		InstanceID: instance.ID,
+		Hostname:   "web",
+		PublicIP:   instance.PublicIP,
+		Username:   InstanceRecoveryUser,
+		Password:   "rescue-" + instance.ID,
+	}))
+
+	after, _ := fake.GetInstance(instance.ID)
+	g.Expect(after.Status).To(Equal(InstanceStatusActive))
+	g.Expect(after.RescuePassword).To(BeEmpty())
+}
+
+func TestRecoverInstanceDisablesOnFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	client, lifecycle := newLifecycleForTesting()
+	instance, _ := client.CreateInstance(&InstanceConfig{Hostname: "web", Size: "g3.xsmall"})
+	lifecycle.WaitForStatus(instance.ID, InstanceStatusActive)
+
+	failed := errors.New("fsck failed")
+	err := lifecycle.Recover(instance.ID, func(credentials *InstanceRecoveryCredentials) error {
+		return failed
+	})
+	g.Expect(errors.Is(err, failed)).To(BeTrue())
+
+	// Recovery mode was still disabled
+	lifecycle.WaitForStatus(instance.ID, InstanceStatusActive)
+	after, _ := client.GetInstance(instance.ID)
+	g.Expect(after.RescuePassword).To(BeEmpty())
+
+	// The rescue environment never booting also disables recovery mode
+	lifecycle.Timeout = time.Minute
+	client.ActionDuration = time.Hour
+	called := false
+	err = lifecycle.Recover(instance.ID, func(credentials *InstanceRecoveryCredentials) error {
+		called = true
+		return nil
+	})
+	g.Expect(errors.Is(err, TimeoutError)).To(BeTrue())
+	g.Expect(called).To(BeFalse())
+	after, _ = client.GetInstance(instance.ID)
+	g.Expect(after.Status).To(Equal(InstanceStatusRebooting))
+}
+
+func TestRecoverInstanceInvalidStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	client, lifecycle := newLifecycleForTesting()
+	instance, _ := client.CreateInstance(&InstanceConfig{Hostname: "web", Size: "g3.xsmall"})
+
+	err := lifecycle.Recover(instance.ID, func(credentials *InstanceRecoveryCredentials) error { return nil })
+	g.Expect(errors.Is(err, InstanceInvalidTransitionError)).To(BeTrue())
+	after, _ := client.GetInstance(instance.ID)
+	g.Expect(after.Status).To(Equal(InstanceStatusBuilding))
+
+	err = lifecycle.Recover(instance.ID, nil)
+	g.Expect(errors.Is(err, ParameterRecoveryWorkMissingError)).To(BeTrue())
+}
@@ -3,8 +3,6 @@ package civogo
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
-	"strings"
 )
 
 // InstanceSize represents an available size for instances to launch
@@ -45,29 +43,5 @@ func (c *Client) FindInstanceSizes(search string) (*InstanceSize, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := InstanceSize{}
-
-	for _, value := range instanceSize {
-		if value.Name == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(instanceSize, search, c.FindMode, func(s InstanceSize) string { return s.Name }, func(s InstanceSize) []string { return nil })
 }
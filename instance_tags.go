@@ -0,0 +1,175 @@
+package civogo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InstanceTag is a tag split into its key and value, following the "key=value"
+// convention. A tag without "=" has only a key.
+type InstanceTag struct {
+	Key   string
+	Value string
+}
+
+// ParseInstanceTag splits a tag such as "env=prod" into its key and value
+func ParseInstanceTag(tag string) InstanceTag {
+	key, value, _ := strings.Cut(tag, "=")
+	return InstanceTag{Key: key, Value: value}
+}
+
+// ParseInstanceTags returns the value of each key=value tag, keyed by the tag's key.
+// Tags without a value are included with an empty value.
+func ParseInstanceTags(tags []string) map[string]string {
+	values := map[string]string{}
+	for _, tag := range tags {
+		parsed := ParseInstanceTag(tag)
+		values[parsed.Key] = parsed.Value
+	}
+	return values
+}
+
+// String returns the tag in its "key=value" form
+func (t InstanceTag) String() string {
+	if t.Value == "" {
+		return t.Key
+	}
+	return t.Key + "=" + t.Value
+}
+
+// HasTag returns true if the instance matches tag, see ListInstancesByTag
+func (i *Instance) HasTag(tag string) bool {
+	return instanceTagMatches(i.Tags, tag)
+}
+
+// TagValue returns the value of the instance's key=value tag with the given key
+func (i *Instance) TagValue(key string) (string, bool) {
+	for _, tag := range i.Tags {
+		if parsed := ParseInstanceTag(tag); parsed.Key == key {
+			return parsed.Value, true
+		}
+	}
+	return "", false
+}
+
+// ReplaceInstanceTags sets the instance's tags to exactly tags
+func (c *Client) ReplaceInstanceTags(id string, tags []string) (*SimpleResponse, error) {
+	if err := validateInstanceTags(tags); err != nil {
+		return nil, err
+	}
+
+	return c.SetInstanceTags(&Instance{ID: id}, strings.Join(tags, " "))
+}
+
+// AddInstanceTags adds tags to the instance, keeping the ones it already has.
+// A key=value tag replaces any existing tag with the same key.
+func (c *Client) AddInstanceTags(id string, tags []string) (*SimpleResponse, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	return c.ReplaceInstanceTags(id, addInstanceTags(instance.Tags, tags))
+}
+
+// RemoveInstanceTags removes tags from the instance, a tag given as a key on
+// its own also removes the key=value tag with that key
+func (c *Client) RemoveInstanceTags(id string, tags []string) (*SimpleResponse, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	return c.ReplaceInstanceTags(id, removeInstanceTags(instance.Tags, tags))
+}
+
+// ListInstancesByTag returns the instances with the given tag. A tag such as
+// "env=prod" must match exactly, while "env" matches both the tag "env" and
+// any "env=..." tag.
+func (c *Client) ListInstancesByTag(tag string) ([]Instance, error) {
+	instances, err := c.ListAllInstances()
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	return filterInstancesByTag(instances, tag), nil
+}
+
+func validateInstanceTags(tags []string) error {
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsAny(tag, " \t\n") {
+			err := fmt.Errorf("tag %q must be non-empty and can't contain whitespace", tag)
+			return ParameterTagInvalidError.wrap(err)
+		}
+		if strings.HasPrefix(tag, "=") {
+			err := fmt.Errorf("tag %q has a value but no key", tag)
+			return ParameterTagInvalidError.wrap(err)
+		}
+	}
+	return nil
+}
+
+func instanceTagMatches(tags []string, search string) bool {
+	wanted := ParseInstanceTag(search)
+	for _, tag := range tags {
+		if tag == search {
+			return true
+		}
+		if !strings.Contains(search, "=") && ParseInstanceTag(tag).Key == wanted.Key {
+			return true
+		}
+	}
+	return false
+}
+
+func filterInstancesByTag(instances []Instance, tag string) []Instance {
+	result := []Instance{}
+	for _, instance := range instances {
+		if instance.HasTag(tag) {
+			result = append(result, instance)
+		}
+	}
+	return result
+}
+
+func addInstanceTags(existing, tags []string) []string {
+	replacedKeys := map[string]bool{}
+	for _, tag := range tags {
+		if strings.Contains(tag, "=") {
+			replacedKeys[ParseInstanceTag(tag).Key] = true
+		}
+	}
+
+	result := []string{}
+	for _, tag := range existing {
+		if tag != "" && !replacedKeys[ParseInstanceTag(tag).Key] {
+			result = append(result, tag)
+		}
+	}
+	for _, tag := range tags {
+		if !findString(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func removeInstanceTags(existing, tags []string) []string {
+	result := []string{}
+	for _, tag := range existing {
+		if tag == "" {
+			continue
+		}
+		removed := false
+		for _, remove := range tags {
+			if instanceTagMatches([]string{tag}, remove) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package civogo
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseInstanceTags(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ParseInstanceTag("env=prod")).To(Equal(InstanceTag{Key: "env", Value: "prod"}))
+	g.Expect(ParseInstanceTag("role=a=b")).To(Equal(InstanceTag{Key: "role", Value: "a=b"}))
+	g.Expect(ParseInstanceTag("web").String()).To(Equal("web"))
+	g.Expect(InstanceTag{Key: "env", Value: "prod"}.String()).To(Equal("env=prod"))
+	g.Expect(ParseInstanceTags([]string{"web", "env=prod"})).To(Equal(map[string]string{"web": "", "env": "prod"}))
+
+	instance := &Instance{Tags: []string{"web", "env=prod"}}
+	value, ok := instance.TagValue("env")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(value).To(Equal("prod"))
+	_, ok = instance.TagValue("team")
+	g.Expect(ok).To(BeFalse())
+
+	g.Expect(instance.HasTag("env")).To(BeTrue())
+	g.Expect(instance.HasTag("env=prod")).To(BeTrue())
+	g.Expect(instance.HasTag("env=dev")).To(BeFalse())
+	g.Expect(instance.HasTag("we")).To(BeFalse())
+}
+
+func TestAddRemoveInstanceTags(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(addInstanceTags([]string{"", "web", "env=prod"}, []string{"env=dev", "web", "db"})).To(Equal([]string{"web", "env=dev", "db"}))
+	g.Expect(removeInstanceTags([]string{"web", "env=prod", "db"}, []string{"env", "db"})).To(Equal([]string{"web"}))
+	g.Expect(removeInstanceTags([]string{"web", "env=prod"}, []string{"env=dev"})).To(Equal([]string{"web", "env=prod"}))
+}
+
+func TestReplaceInstanceTags(t *testing.T) {
+	client, server, _ := NewAdvancedClientForTesting([]ConfigAdvanceClientForTesting{
+		{
+			Method: "PUT",
+			Value: []ValueAdvanceClientForTesting{
+				{
+					RequestBody:  `{"tags":"web env=prod","region":"TEST"}`,
+					URL:          "/v2/instances/12345/tags",
+					ResponseBody: `{"result": "success"}`,
+				},
+			},
+		},
+	})
+	defer server.Close()
+
+	got, err := client.ReplaceInstanceTags("12345", []string{"web", "env=prod"})
+	if err != nil {
+		t.Errorf("Request returned an error: %s", err)
+		return
+	}
+	if got.Result != "success" {
+		t.Errorf("Expected %s, got %s", "success", got.Result)
+	}
+}
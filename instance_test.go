@@ -75,8 +75,8 @@ func TestFindInstance(t *testing.T) {
 	}
 
 	_, err := client.FindInstance("com")
-	if err.Error() != "MultipleMatchesError: unable to find com because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find com because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find com because there were multiple matches: foo.example.com (12345), bar.zip.com (67890), baz.zip.com (98765), zip.com (43210)" {
+		t.Errorf("Expected %s, got %s", "unable to find com because there were multiple matches: foo.example.com (12345), bar.zip.com (67890), baz.zip.com (98765), zip.com (43210)", err.Error())
 	}
 
 	got, _ = client.FindInstance("zip.com")
@@ -255,7 +255,7 @@ func TestUpdateInstance(t *testing.T) {
 			Method: "PUT",
 			Value: []ValueAdvanceClientForTesting{
 				{
-					RequestBody:  `{"hostname":"dummy.example.com","notes":"my notes","reverse_dns":"dummy-reverse.example.com", "subnets": ["test-subnet-1", "test-subnet-2"]}`,
+					RequestBody:  `{"hostname":"dummy.example.com","notes":"my notes","public_ip":"","region":"TEST","reverse_dns":"dummy-reverse.example.com","subnets":null}`,
 					URL:          "/v2/instances/12345",
 					ResponseBody: `{"result": "success"}`,
 				},
@@ -413,7 +413,7 @@ func TestUpgradeInstance(t *testing.T) {
 			Method: "PUT",
 			Value: []ValueAdvanceClientForTesting{
 				{
-					RequestBody:  `{"size":"g99.huge"}`,
+					RequestBody:  `{"size":"g99.huge","region":"TEST"}`,
 					URL:          "/v2/instances/12345/resize",
 					ResponseBody: `{"result": "success"}`,
 				},
@@ -481,7 +481,7 @@ func TestSetInstanceFirewall(t *testing.T) {
 			Method: "PUT",
 			Value: []ValueAdvanceClientForTesting{
 				{
-					RequestBody:  `{"firewall_id":"67890"}`,
+					RequestBody:  `{"firewall_id":"67890","region":"TEST"}`,
 					URL:          "/v2/instances/12345/firewall",
 					ResponseBody: `{"result": "success"}`,
 				},
@@ -530,7 +530,7 @@ func TestUpdateInstanceAllowedIPs(t *testing.T) {
 			Method: "PUT",
 			Value: []ValueAdvanceClientForTesting{
 				{
-					RequestBody:  `"{"allowed_ips": ["192.168.1.10", "192.168.1.11"]"`,
+					RequestBody:  `{"allowed_ips": ["192.168.1.10", "192.168.1.11"]}`,
 					URL:          "/v2/instances/12345/allowed_ips",
 					ResponseBody: `{"result": "success"}`,
 				},
@@ -549,7 +549,7 @@ func TestUpdateInstanceBandwidth(t *testing.T) {
 			Method: "PUT",
 			Value: []ValueAdvanceClientForTesting{
 				{
-					RequestBody:  `"{"network_bandwidth_limit":"10"}"`,
+					RequestBody:  `{"network_bandwidth_limit":10}`,
 					URL:          "/v2/instances/12345/network_bandwidth_limit",
 					ResponseBody: `{"result": "success"}`,
 				},
@@ -0,0 +1,383 @@
+package civogo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Limits on how long a VNC session can be requested for
+const (
+	MinInstanceVncDuration = time.Minute
+	MaxInstanceVncDuration = 24 * time.Hour
+)
+
+// minInstanceVncRenewDelay stops AutoRenew renewing in a tight loop when the
+// API returns a session which is already due for renewal
+const minInstanceVncRenewDelay = time.Second
+
+// InstanceVncSessionOptions controls how OpenInstanceVncSession opens and renews a session
+type InstanceVncSessionOptions struct {
+	// Duration is how long the session lasts before it needs renewing, zero uses the API's default
+	Duration time.Duration
+	// AutoRenew extends the session shortly before it expires, until it's closed
+	AutoRenew bool
+	// RenewBefore is how long before expiry the session is renewed, it must be
+	// less than Duration and defaults to a minute or half of Duration if that's shorter
+	RenewBefore time.Duration
+}
+
+// InstanceVncSession is an open VNC console session for an instance. It's closed,
+// deleting the session in the API, when Close is called or the context passed
+// to OpenInstanceVncSession is cancelled.
+type InstanceVncSession struct {
+	InstanceID string
+
+	client  *Client
+	options InstanceVncSessionOptions
+	mu      sync.Mutex
+	uri     string
+	expires time.Time
+	err     error
+	done    chan struct{}
+	cancel  context.CancelFunc
+	closing sync.Once
+}
+
+// ValidateInstanceVncDuration returns InstanceVncDurationInvalidError if d is
+// outside MinInstanceVncDuration and MaxInstanceVncDuration
+func ValidateInstanceVncDuration(d time.Duration) error {
+	if d < MinInstanceVncDuration || d > MaxInstanceVncDuration {
+		err := fmt.Errorf("VNC session duration %s must be between %s and %s", d, MinInstanceVncDuration, MaxInstanceVncDuration)
+		return InstanceVncDurationInvalidError.wrap(err)
+	}
+	return nil
+}
+
+// ParseInstanceVncExpiration parses InstanceVnc.Expiration
+func ParseInstanceVncExpiration(expiration string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05 -0700", "2006-01-02 15:04:05 MST"} {
+		if t, err := time.Parse(layout, expiration); err == nil {
+			return t, nil
+		}
+	}
+	err := fmt.Errorf("unable to parse VNC expiration %q", expiration)
+	return time.Time{}, ResponseDecodeFailedError.wrap(err)
+}
+
+// OpenInstanceVncSession enables VNC for the instance and returns the session
+func (c *Client) OpenInstanceVncSession(ctx context.Context, id string, options *InstanceVncSessionOptions) (*InstanceVncSession, error) {
+	opts := InstanceVncSessionOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Duration != 0 {
+		if err := ValidateInstanceVncDuration(opts.Duration); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case opts.RenewBefore == 0:
+		opts.RenewBefore = time.Minute
+		if opts.Duration != 0 && opts.Duration <= opts.RenewBefore {
+			opts.RenewBefore = opts.Duration / 2
+		}
+	case opts.RenewBefore < 0:
+		err := fmt.Errorf("VNC session renewal time %s can't be negative", opts.RenewBefore)
+		return nil, InstanceVncDurationInvalidError.wrap(err)
+	case opts.Duration != 0 && opts.RenewBefore >= opts.Duration:
+		err := fmt.Errorf("VNC session renewal time %s must be less than its duration %s", opts.RenewBefore, opts.Duration)
+		return nil, InstanceVncDurationInvalidError.wrap(err)
+	}
+
+	// The session renews in the background, so it has its own copy of the client
+	session := &InstanceVncSession{
+		InstanceID: id,
+		client:     c.clone(),
+		options:    opts,
+		done:       make(chan struct{}),
+	}
+	if err := session.enable(); err != nil {
+		return nil, err
+	}
+
+	ctx, session.cancel = context.WithCancel(ctx)
+	go session.run(ctx)
+	return session, nil
+}
+
+// URI returns the current console URI
+func (s *InstanceVncSession) URI() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uri
+}
+
+// ExpiresAt returns when the session expires unless it's renewed
+func (s *InstanceVncSession) ExpiresAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expires
+}
+
+// Done is closed once the session has been closed
+func (s *InstanceVncSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the session ended early, such as a failed renewal
+func (s *InstanceVncSession) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Renew extends the session by its duration
+func (s *InstanceVncSession) Renew() error {
+	return s.enable()
+}
+
+// Close ends the session, deleting it in the API, and waits for it to finish
+func (s *InstanceVncSession) Close() error {
+	s.cancel()
+	<-s.done
+	return s.Err()
+}
+
+func (s *InstanceVncSession) enable() error {
+	duration := []string{}
+	if s.options.Duration != 0 {
+		duration = append(duration, s.options.Duration.String())
+	}
+
+	vnc, err := s.client.GetInstanceVnc(s.InstanceID, duration...)
+	if err != nil {
+		return err
+	}
+
+	expires := time.Time{}
+	status, err := s.client.GetInstanceVncStatus(s.InstanceID)
+	if err == nil && status.Expiration != "" {
+		expires, err = ParseInstanceVncExpiration(status.Expiration)
+	}
+	if err != nil {
+		return err
+	}
+	if expires.IsZero() && s.options.Duration != 0 {
+		expires = time.Now().Add(s.options.Duration)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uri = vnc.URI
+	if status.URI != "" {
+		s.uri = status.URI
+	}
+	s.expires = expires
+	return nil
+}
+
+func (s *InstanceVncSession) run(ctx context.Context) {
+	defer close(s.done)
+
+	for {
+		var renew <-chan time.Time
+		var timer *time.Timer
+		if expires := s.ExpiresAt(); s.options.AutoRenew && !expires.IsZero() {
+			timer = time.NewTimer(renewDelay(expires, s.options.RenewBefore))
+			renew = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			s.end(nil)
+			return
+		case <-renew:
+			if err := s.enable(); err != nil {
+				s.end(err)
+				return
+			}
+		}
+	}
+}
+
+// renewDelay is how long until a session expiring at expires is renewed. If
+// that's already passed, it waits half of the time left, but at least minInstanceVncRenewDelay.
+func renewDelay(expires time.Time, renewBefore time.Duration) time.Duration {
+	if delay := time.Until(expires.Add(-renewBefore)); delay > 0 {
+		return delay
+	}
+	return max(time.Until(expires)/2, minInstanceVncRenewDelay)
+}
+
+// end deletes the session in the API, recording err or the error deleting it
+func (s *InstanceVncSession) end(err error) {
+	s.closing.Do(func() {
+		if _, deleteErr := s.client.DeleteInstanceVncSession(s.InstanceID); err == nil {
+			err = deleteErr
+		}
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+	})
+}
+
+// InstanceVncProxy accepts local TCP connections, such as from a standard VNC
+// viewer, and relays each of them over a websocket to the session's console
+type InstanceVncProxy struct {
+	listener net.Listener
+	session  *InstanceVncSession
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	closed   bool
+	closing  chan struct{}
+}
+
+// Proxy listens on addr (for example "127.0.0.1:5900") and relays each
+// connection to the console until the session or the proxy is closed
+func (s *InstanceVncSession) Proxy(addr string) (*InstanceVncProxy, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := &InstanceVncProxy{listener: listener, session: s, conns: map[net.Conn]bool{}, closing: make(chan struct{})}
+	proxy.wg.Add(1)
+	go proxy.accept()
+	go func() {
+		select {
+		case <-s.Done():
+			proxy.Close()
+		case <-proxy.closing:
+		}
+	}()
+	return proxy, nil
+}
+
+// Addr returns the local address VNC viewers should connect to
+func (p *InstanceVncProxy) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+// Close stops accepting connections and closes the open ones, including any
+// still connecting to the console
+func (p *InstanceVncProxy) Close() error {
+	err := p.listener.Close()
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.closing)
+	}
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+	return err
+}
+
+func (p *InstanceVncProxy) accept() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.wg.Add(1)
+		go p.relay(conn)
+	}
+}
+
+func (p *InstanceVncProxy) relay(conn net.Conn) {
+	defer p.wg.Done()
+	defer conn.Close()
+
+	ws, err := dialInstanceVncWebsocket(p.session.URI())
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	if !p.track(conn, ws) {
+		return
+	}
+	defer p.untrack(conn, ws)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(ws, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, ws)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// track records the relay's connections so Close can close them, returning
+// false if the proxy or session has already been closed. The relay closes its
+// own connections then.
+func (p *InstanceVncProxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	select {
+	case <-p.session.Done():
+		return false
+	default:
+	}
+	for _, conn := range conns {
+		p.conns[conn] = true
+	}
+	return true
+}
+
+func (p *InstanceVncProxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range conns {
+		delete(p.conns, conn)
+	}
+}
+
+// dialInstanceVncWebsocket connects to the console URI, switching http(s) to ws(s),
+// using the "binary" subprotocol websockify expects
+func dialInstanceVncWebsocket(uri string) (*websocket.Conn, error) {
+	target, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	origin := &url.URL{Scheme: target.Scheme, Host: target.Host}
+	switch target.Scheme {
+	case "https":
+		target.Scheme = "wss"
+	case "http":
+		target.Scheme = "ws"
+	}
+
+	config, err := websocket.NewConfig(target.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+	config.Protocol = []string{"binary"}
+
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
@@ -0,0 +1,225 @@
+package civogo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/websocket"
+)
+
+// fakeVncAPI serves the instance VNC endpoints, with each PUT extending the
+// session by its duration, or lifetime if it's set, and a websocket echoing
+// whatever it's sent. When hold is set, each websocket signals dialing and waits
+// for hold to be closed before it's accepted.
+type fakeVncAPI struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	renewals int
+	deleted  int
+	expires  time.Time
+	lifetime time.Duration
+	dialing  chan struct{}
+	hold     chan struct{}
+}
+
+func newFakeVncAPI() *fakeVncAPI {
+	api := &fakeVncAPI{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v2/instances/{id}/vnc", func(w http.ResponseWriter, r *http.Request) {
+		duration, err := time.ParseDuration(r.URL.Query().Get("duration"))
+		if err != nil {
+			duration = time.Hour
+		}
+		api.mu.Lock()
+		api.renewals++
+		api.expires = time.Now().Add(duration)
+		if api.lifetime != 0 {
+			api.expires = time.Now().Add(api.lifetime)
+		}
+		api.mu.Unlock()
+		w.Write([]byte(`{"uri": "` + api.server.URL + `/console", "duration": "` + duration.String() + `"}`))
+	})
+	mux.HandleFunc("GET /v2/instances/{id}/vnc", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		w.Write([]byte(`{"uri": "` + api.server.URL + `/console", "expiration": "` + api.expires.Format(time.RFC3339Nano) + `"}`))
+	})
+	mux.HandleFunc("DELETE /v2/instances/{id}/vnc", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		api.deleted++
+		api.mu.Unlock()
+		w.Write([]byte(`{"result": "success"}`))
+	})
+	echo := websocket.Handler(func(ws *websocket.Conn) {
+		io.Copy(ws, ws)
+	})
+	mux.HandleFunc("/console", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		dialing, hold := api.dialing, api.hold
+		api.mu.Unlock()
+		if hold != nil {
+			dialing <- struct{}{}
+			<-hold
+		}
+		echo.ServeHTTP(w, r)
+	})
+	api.server = httptest.NewServer(mux)
+	return api
+}
+
+func (api *fakeVncAPI) counts() (int, int) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.renewals, api.deleted
+}
+
+func TestValidateInstanceVncDuration(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ValidateInstanceVncDuration(30 * time.Minute)).To(Succeed())
+	g.Expect(errors.Is(ValidateInstanceVncDuration(time.Second), InstanceVncDurationInvalidError)).To(BeTrue())
+	g.Expect(errors.Is(ValidateInstanceVncDuration(48*time.Hour), InstanceVncDurationInvalidError)).To(BeTrue())
+
+	expires, err := ParseInstanceVncExpiration("2024-01-01T12:30:00Z")
+	g.Expect(err).To(BeNil())
+	g.Expect(expires).To(Equal(time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)))
+
+	_, err = ParseInstanceVncExpiration("tomorrow")
+	g.Expect(errors.Is(err, ResponseDecodeFailedError)).To(BeTrue())
+}
+
+func TestOpenInstanceVncSessionCloses(t *testing.T) {
+	g := NewWithT(t)
+
+	api := newFakeVncAPI()
+	defer api.server.Close()
+	client, _ := NewClientForTestingWithServer(api.server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session, err := client.OpenInstanceVncSession(ctx, "12345", &InstanceVncSessionOptions{Duration: 30 * time.Minute})
+	g.Expect(err).To(BeNil())
+	g.Expect(session.URI()).To(Equal(api.server.URL + "/console"))
+	g.Expect(session.ExpiresAt()).To(BeTemporally("~", time.Now().Add(30*time.Minute), 5*time.Second))
+
+	cancel()
+	g.Eventually(session.Done()).Should(BeClosed())
+	g.Expect(session.Err()).To(BeNil())
+	_, deleted := api.counts()
+	g.Expect(deleted).To(Equal(1))
+
+	// Closing again doesn't delete the session twice
+	g.Expect(session.Close()).To(Succeed())
+	_, deleted = api.counts()
+	g.Expect(deleted).To(Equal(1))
+
+	_, err = client.OpenInstanceVncSession(context.Background(), "12345", &InstanceVncSessionOptions{Duration: time.Second})
+	g.Expect(errors.Is(err, InstanceVncDurationInvalidError)).To(BeTrue())
+	_, err = client.OpenInstanceVncSession(context.Background(), "12345", &InstanceVncSessionOptions{Duration: time.Minute, RenewBefore: time.Minute})
+	g.Expect(err).To(MatchError("InstanceVncDurationInvalidError: VNC session renewal time 1m0s must be less than its duration 1m0s"))
+	_, err = client.OpenInstanceVncSession(context.Background(), "12345", &InstanceVncSessionOptions{RenewBefore: -time.Minute})
+	g.Expect(errors.Is(err, InstanceVncDurationInvalidError)).To(BeTrue())
+}
+
+func TestOpenInstanceVncSessionAutoRenews(t *testing.T) {
+	g := NewWithT(t)
+
+	api := newFakeVncAPI()
+	defer api.server.Close()
+	client, _ := NewClientForTestingWithServer(api.server)
+
+	// The session the API returns is already due for renewal, so it's renewed
+	// after half of its lifetime, rather than straight away in a tight loop
+	api.lifetime = time.Second
+	session, err := client.OpenInstanceVncSession(context.Background(), "12345", &InstanceVncSessionOptions{
+		Duration:    time.Minute,
+		AutoRenew:   true,
+		RenewBefore: 30 * time.Second,
+	})
+	g.Expect(err).To(BeNil())
+	renewals := func() int {
+		renewals, _ := api.counts()
+		return renewals
+	}
+	g.Consistently(renewals, 500*time.Millisecond).Should(Equal(1))
+	g.Eventually(renewals, 2*time.Second).Should(Equal(2))
+
+	g.Expect(session.Close()).To(Succeed())
+	_, deleted := api.counts()
+	g.Expect(deleted).To(Equal(1))
+}
+
+func TestInstanceVncSessionProxy(t *testing.T) {
+	g := NewWithT(t)
+
+	api := newFakeVncAPI()
+	defer api.server.Close()
+	client, _ := NewClientForTestingWithServer(api.server)
+
+	session, err := client.OpenInstanceVncSession(context.Background(), "12345", nil)
+	g.Expect(err).To(BeNil())
+
+	proxy, err := session.Proxy("127.0.0.1:0")
+	g.Expect(err).To(BeNil())
+
+	conn, err := net.Dial("tcp", proxy.Addr().String())
+	g.Expect(err).To(BeNil())
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("RFB 003.008\n"))
+	g.Expect(err).To(BeNil())
+	reply := make([]byte, 12)
+	_, err = io.ReadFull(conn, reply)
+	g.Expect(err).To(BeNil())
+	g.Expect(string(reply)).To(Equal("RFB 003.008\n"))
+
+	// Closing the session closes the proxy and its connections
+	g.Expect(session.Close()).To(Succeed())
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(reply)
+	g.Expect(err).To(HaveOccurred())
+	_, err = net.Dial("tcp", proxy.Addr().String())
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestInstanceVncProxyCloseWhileDialling(t *testing.T) {
+	g := NewWithT(t)
+
+	api := newFakeVncAPI()
+	defer api.server.Close()
+	client, _ := NewClientForTestingWithServer(api.server)
+
+	session, err := client.OpenInstanceVncSession(context.Background(), "12345", nil)
+	g.Expect(err).To(BeNil())
+	defer session.Close()
+
+	proxy, err := session.Proxy("127.0.0.1:0")
+	g.Expect(err).To(BeNil())
+
+	api.mu.Lock()
+	api.dialing, api.hold = make(chan struct{}), make(chan struct{})
+	api.mu.Unlock()
+	conn, err := net.Dial("tcp", proxy.Addr().String())
+	g.Expect(err).To(BeNil())
+	defer conn.Close()
+	g.Eventually(api.dialing).Should(Receive())
+
+	// Close waits for the relay to finish dialling, which then gives up rather
+	// than relaying a connection nothing would close
+	closed := make(chan error, 1)
+	go func() { closed <- proxy.Close() }()
+	g.Consistently(closed, 100*time.Millisecond).ShouldNot(Receive())
+	close(api.hold)
+	g.Eventually(closed, 2*time.Second).Should(Receive())
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	g.Expect(err).To(Equal(io.EOF))
+}
@@ -0,0 +1,408 @@
+package civogo
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultKubernetesAPIPort is the port the Kubernetes API listens on in Civo clusters
+const DefaultKubernetesAPIPort = "6443"
+
+// Kubeconfig is a typed kubeconfig file. Fields this package doesn't use are
+// kept in Extra so they survive a round trip.
+type Kubeconfig struct {
+	APIVersion     string                   `yaml:"apiVersion,omitempty"`
+	Kind           string                   `yaml:"kind,omitempty"`
+	Clusters       []KubeconfigNamedCluster `yaml:"clusters"`
+	Contexts       []KubeconfigNamedContext `yaml:"contexts"`
+	Users          []KubeconfigNamedUser    `yaml:"users"`
+	CurrentContext string                   `yaml:"current-context"`
+	Extra          map[string]interface{}   `yaml:",inline"`
+}
+
+// KubeconfigNamedCluster is an entry in Kubeconfig.Clusters
+type KubeconfigNamedCluster struct {
+	Name    string            `yaml:"name"`
+	Cluster KubeconfigCluster `yaml:"cluster"`
+}
+
+// KubeconfigCluster is how to reach a cluster's API server
+type KubeconfigCluster struct {
+	Server                   string                 `yaml:"server"`
+	CertificateAuthorityData string                 `yaml:"certificate-authority-data,omitempty"`
+	CertificateAuthority     string                 `yaml:"certificate-authority,omitempty"`
+	InsecureSkipTLSVerify    bool                   `yaml:"insecure-skip-tls-verify,omitempty"`
+	TLSServerName            string                 `yaml:"tls-server-name,omitempty"`
+	ProxyURL                 string                 `yaml:"proxy-url,omitempty"`
+	Extra                    map[string]interface{} `yaml:",inline"`
+}
+
+// KubeconfigNamedContext is an entry in Kubeconfig.Contexts
+type KubeconfigNamedContext struct {
+	Name    string            `yaml:"name"`
+	Context KubeconfigContext `yaml:"context"`
+}
+
+// KubeconfigContext pairs a cluster with the user to access it as
+type KubeconfigContext struct {
+	Cluster   string                 `yaml:"cluster"`
+	User      string                 `yaml:"user"`
+	Namespace string                 `yaml:"namespace,omitempty"`
+	Extra     map[string]interface{} `yaml:",inline"`
+}
+
+// KubeconfigNamedUser is an entry in Kubeconfig.Users
+type KubeconfigNamedUser struct {
+	Name string         `yaml:"name"`
+	User KubeconfigUser `yaml:"user"`
+}
+
+// KubeconfigUser is the credentials for a user
+type KubeconfigUser struct {
+	ClientCertificateData string                 `yaml:"client-certificate-data,omitempty"`
+	ClientKeyData         string                 `yaml:"client-key-data,omitempty"`
+	ClientCertificate     string                 `yaml:"client-certificate,omitempty"`
+	ClientKey             string                 `yaml:"client-key,omitempty"`
+	Token                 string                 `yaml:"token,omitempty"`
+	TokenFile             string                 `yaml:"tokenFile,omitempty"`
+	Username              string                 `yaml:"username,omitempty"`
+	Password              string                 `yaml:"password,omitempty"`
+	Extra                 map[string]interface{} `yaml:",inline"`
+}
+
+// KubeRESTConfig holds the fields of k8s.io/client-go/rest.Config a kubeconfig
+// sets, with the same names and types, so it can be copied into one without
+// this package depending on client-go
+type KubeRESTConfig struct {
+	Host            string
+	Username        string
+	Password        string
+	BearerToken     string
+	BearerTokenFile string
+	TLSClientConfig KubeTLSClientConfig
+}
+
+// KubeTLSClientConfig matches k8s.io/client-go/rest.TLSClientConfig
+type KubeTLSClientConfig struct {
+	Insecure   bool
+	ServerName string
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	CertData   []byte
+	KeyData    []byte
+	CAData     []byte
+}
+
+// KubeconfigMergeOptions controls how a cluster's kubeconfig is merged into a file
+type KubeconfigMergeOptions struct {
+	// ContextName names the context, cluster and user, defaulting to the cluster's name
+	ContextName string
+	// Server replaces the API server address, see Kubeconfig.SetServer
+	Server string
+	// SetCurrentContext makes the merged context the current one
+	SetCurrentContext bool
+}
+
+// ParseKubeconfig decodes a kubeconfig
+func ParseKubeconfig(data string) (*Kubeconfig, error) {
+	config := &Kubeconfig{}
+	if err := yaml.Unmarshal([]byte(data), config); err != nil {
+		return nil, KubeconfigInvalidError.wrap(err)
+	}
+	if len(config.Clusters) == 0 {
+		err := errors.New("kubeconfig has no clusters")
+		return nil, KubeconfigInvalidError.wrap(err)
+	}
+	return config, nil
+}
+
+// ParseKubeConfig decodes the cluster's KubeConfig
+func (k *KubernetesCluster) ParseKubeConfig() (*Kubeconfig, error) {
+	if strings.TrimSpace(k.KubeConfig) == "" {
+		err := fmt.Errorf("cluster %s has no kubeconfig, it may still be building", k.Name)
+		return nil, KubeconfigInvalidError.wrap(err)
+	}
+	return ParseKubeconfig(k.KubeConfig)
+}
+
+// Marshal encodes the kubeconfig as YAML
+func (c *Kubeconfig) Marshal() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// Cluster returns the cluster and user for the named context, or the current context if name is empty
+func (c *Kubeconfig) Cluster(name string) (*KubeconfigCluster, *KubeconfigUser, error) {
+	context, err := c.context(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cluster *KubeconfigCluster
+	for i := range c.Clusters {
+		if c.Clusters[i].Name == context.Context.Cluster {
+			cluster = &c.Clusters[i].Cluster
+		}
+	}
+	if cluster == nil {
+		err := fmt.Errorf("context %s refers to cluster %s which doesn't exist", context.Name, context.Context.Cluster)
+		return nil, nil, KubeconfigInvalidError.wrap(err)
+	}
+
+	user := &KubeconfigUser{}
+	for i := range c.Users {
+		if c.Users[i].Name == context.Context.User {
+			user = &c.Users[i].User
+		}
+	}
+	return cluster, user, nil
+}
+
+// SetServer points the current context's cluster at server. A bare host or IP,
+// such as a cluster's private IP, becomes https://host:6443.
+func (c *Kubeconfig) SetServer(server string) error {
+	cluster, _, err := c.Cluster("")
+	if err != nil {
+		return err
+	}
+	cluster.Server = KubernetesAPIServerURL(server)
+	return nil
+}
+
+// KubernetesAPIServerURL adds the https scheme and API port to a host if they're missing
+func KubernetesAPIServerURL(server string) string {
+	if ip := net.ParseIP(server); ip != nil {
+		return "https://" + net.JoinHostPort(ip.String(), DefaultKubernetesAPIPort)
+	}
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil || u.Port() != "" {
+		return server
+	}
+	u.Host = net.JoinHostPort(strings.Trim(u.Hostname(), "[]"), DefaultKubernetesAPIPort)
+	return u.String()
+}
+
+// Rename gives the current context, and the cluster and user it refers to, the
+// same name. Other contexts which share the cluster or user are updated to the
+// new names too. It returns KubeconfigInvalidError if another context, cluster
+// or user already has the name.
+func (c *Kubeconfig) Rename(name string) error {
+	context, err := c.context("")
+	if err != nil {
+		return err
+	}
+	cluster, user := context.Context.Cluster, context.Context.User
+
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name && &c.Contexts[i] != context {
+			err := fmt.Errorf("unable to rename context %s to %s, another context already has that name", context.Name, name)
+			return KubeconfigInvalidError.wrap(err)
+		}
+	}
+	for _, entry := range c.Clusters {
+		if entry.Name == name && entry.Name != cluster {
+			err := fmt.Errorf("unable to rename cluster %s to %s, another cluster already has that name", cluster, name)
+			return KubeconfigInvalidError.wrap(err)
+		}
+	}
+	for _, entry := range c.Users {
+		if entry.Name == name && entry.Name != user {
+			err := fmt.Errorf("unable to rename user %s to %s, another user already has that name", user, name)
+			return KubeconfigInvalidError.wrap(err)
+		}
+	}
+
+	for i := range c.Clusters {
+		if c.Clusters[i].Name == cluster {
+			c.Clusters[i].Name = name
+		}
+	}
+	for i := range c.Users {
+		if c.Users[i].Name == user {
+			c.Users[i].Name = name
+		}
+	}
+	for i := range c.Contexts {
+		if c.Contexts[i].Context.Cluster == cluster {
+			c.Contexts[i].Context.Cluster = name
+		}
+		if c.Contexts[i].Context.User == user {
+			c.Contexts[i].Context.User = name
+		}
+	}
+	context.Name = name
+	c.CurrentContext = name
+	return nil
+}
+
+// Merge adds other's clusters, contexts and users, replacing any with the same
+// name, and optionally makes other's current context the current one
+func (c *Kubeconfig) Merge(other *Kubeconfig, setCurrentContext bool) {
+	for _, cluster := range other.Clusters {
+		c.Clusters = replaceKubeconfigEntry(c.Clusters, cluster, func(e KubeconfigNamedCluster) string { return e.Name })
+	}
+	for _, context := range other.Contexts {
+		c.Contexts = replaceKubeconfigEntry(c.Contexts, context, func(e KubeconfigNamedContext) string { return e.Name })
+	}
+	for _, user := range other.Users {
+		c.Users = replaceKubeconfigEntry(c.Users, user, func(e KubeconfigNamedUser) string { return e.Name })
+	}
+	if setCurrentContext || c.CurrentContext == "" {
+		c.CurrentContext = other.CurrentContext
+	}
+}
+
+// RESTConfig returns the connection details for the named context, or the
+// current context if name is empty
+func (c *Kubeconfig) RESTConfig(name string) (*KubeRESTConfig, error) {
+	cluster, user, err := c.Cluster(name)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &KubeRESTConfig{
+		Host:            cluster.Server,
+		Username:        user.Username,
+		Password:        user.Password,
+		BearerToken:     user.Token,
+		BearerTokenFile: user.TokenFile,
+		TLSClientConfig: KubeTLSClientConfig{
+			Insecure:   cluster.InsecureSkipTLSVerify,
+			ServerName: cluster.TLSServerName,
+			CertFile:   user.ClientCertificate,
+			KeyFile:    user.ClientKey,
+			CAFile:     cluster.CertificateAuthority,
+		},
+	}
+
+	decoded := []struct {
+		name, value string
+		to          *[]byte
+	}{
+		{"certificate-authority-data", cluster.CertificateAuthorityData, &config.TLSClientConfig.CAData},
+		{"client-certificate-data", user.ClientCertificateData, &config.TLSClientConfig.CertData},
+		{"client-key-data", user.ClientKeyData, &config.TLSClientConfig.KeyData},
+	}
+	for _, field := range decoded {
+		if field.value == "" {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(field.value)
+		if err != nil {
+			err := fmt.Errorf("%s isn't valid base64: %w", field.name, err)
+			return nil, KubeconfigInvalidError.wrap(err)
+		}
+		*field.to = data
+	}
+	return config, nil
+}
+
+// DefaultKubeconfigPath returns the first file in $KUBECONFIG, or ~/.kube/config
+func DefaultKubeconfigPath() (string, error) {
+	for _, path := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if path != "" {
+			return path, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// MergeKubeconfigFile merges config into the kubeconfig file at path, creating
+// it if it doesn't exist. An empty path uses DefaultKubeconfigPath.
+func MergeKubeconfigFile(path string, config *Kubeconfig, setCurrentContext bool) error {
+	if path == "" {
+		var err error
+		if path, err = DefaultKubeconfigPath(); err != nil {
+			return err
+		}
+	}
+
+	existing := &Kubeconfig{APIVersion: "v1", Kind: "Config"}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, existing); err != nil {
+			err := fmt.Errorf("unable to parse %s: %w", path, err)
+			return KubeconfigInvalidError.wrap(err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	existing.Merge(config, setCurrentContext)
+	out, err := existing.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o600)
+}
+
+// MergeKubeConfig merges the cluster's kubeconfig into the file at path (or
+// the default kubeconfig if it's empty), naming its context after the cluster
+func (k *KubernetesCluster) MergeKubeConfig(path string, options *KubeconfigMergeOptions) error {
+	if options == nil {
+		options = &KubeconfigMergeOptions{}
+	}
+
+	config, err := k.ParseKubeConfig()
+	if err != nil {
+		return err
+	}
+	name := options.ContextName
+	if name == "" {
+		name = k.Name
+	}
+	if err := config.Rename(name); err != nil {
+		return err
+	}
+	if options.Server != "" {
+		if err := config.SetServer(options.Server); err != nil {
+			return err
+		}
+	}
+	return MergeKubeconfigFile(path, config, options.SetCurrentContext)
+}
+
+func (c *Kubeconfig) context(name string) (*KubeconfigNamedContext, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" && len(c.Contexts) == 1 {
+		return &c.Contexts[0], nil
+	}
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i], nil
+		}
+	}
+	err := fmt.Errorf("unable to find context %q", name)
+	return nil, KubeconfigInvalidError.wrap(err)
+}
+
+func replaceKubeconfigEntry[T any](entries []T, entry T, name func(T) string) []T {
+	for i := range entries {
+		if name(entries[i]) == name(entry) {
+			entries[i] = entry
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
@@ -0,0 +1,188 @@
+package civogo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// testKubeconfig is the shape of a kubeconfig returned for a Civo cluster,
+// the data fields are base64 of "ca", "cert" and "key"
+const testKubeconfig = `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: Y2E=
+    server: https://74.220.1.1:6443
+  name: default
+contexts:
+- context:
+    cluster: default
+    user: default
+  name: default
+current-context: default
+kind: Config
+preferences: {}
+users:
+- name: default
+  user:
+    client-certificate-data: Y2VydA==
+    client-key-data: a2V5
+`
+
+func TestParseKubeconfig(t *testing.T) {
+	g := NewWithT(t)
+
+	config, err := ParseKubeconfig(testKubeconfig)
+	g.Expect(err).To(BeNil())
+	g.Expect(config.Clusters[0].Cluster.Server).To(Equal("https://74.220.1.1:6443"))
+
+	rest, err := config.RESTConfig("")
+	g.Expect(err).To(BeNil())
+	g.Expect(rest).To(Equal(&KubeRESTConfig{
+		Host:            "https://74.220.1.1:6443",
+		TLSClientConfig: KubeTLSClientConfig{CAData: []byte("ca"), CertData: []byte("cert"), KeyData: []byte("key")},
+	}))
+
+	// Fields without their own struct field survive a round trip
+	out, err := config.Marshal()
+	g.Expect(err).To(BeNil())
+	g.Expect(string(out)).To(ContainSubstring("preferences: {}"))
+
+	_, err = ParseKubeconfig("kind: Config\n")
+	g.Expect(errors.Is(err, KubeconfigInvalidError)).To(BeTrue())
+	_, err = (&KubernetesCluster{Name: "building"}).ParseKubeConfig()
+	g.Expect(err).To(MatchError("KubeconfigInvalidError: cluster building has no kubeconfig, it may still be building"))
+	_, err = config.RESTConfig("missing")
+	g.Expect(errors.Is(err, KubeconfigInvalidError)).To(BeTrue())
+}
+
+func TestKubeconfigSetServer(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(KubernetesAPIServerURL("192.168.1.5")).To(Equal("https://192.168.1.5:6443"))
+	g.Expect(KubernetesAPIServerURL("https://api.example.com")).To(Equal("https://api.example.com:6443"))
+	g.Expect(KubernetesAPIServerURL("https://api.example.com:443")).To(Equal("https://api.example.com:443"))
+	g.Expect(KubernetesAPIServerURL("fd00::1")).To(Equal("https://[fd00::1]:6443"))
+
+	config, _ := ParseKubeconfig(testKubeconfig)
+	g.Expect(config.SetServer("192.168.1.5")).To(Succeed())
+	g.Expect(config.Clusters[0].Cluster.Server).To(Equal("https://192.168.1.5:6443"))
+}
+
+func TestKubeconfigRenameUpdatesSharedEntries(t *testing.T) {
+	g := NewWithT(t)
+
+	config, _ := ParseKubeconfig(testKubeconfig)
+	config.Contexts = append(config.Contexts, KubeconfigNamedContext{
+		Name:    "default-kube-system",
+		Context: KubeconfigContext{Cluster: "default", User: "default", Namespace: "kube-system"},
+	})
+
+	g.Expect(config.Rename("production")).To(Succeed())
+	g.Expect(config.CurrentContext).To(Equal("production"))
+	g.Expect(config.Clusters[0].Name).To(Equal("production"))
+	g.Expect(config.Users[0].Name).To(Equal("production"))
+	g.Expect(config.Contexts).To(Equal([]KubeconfigNamedContext{
+		{Name: "production", Context: KubeconfigContext{Cluster: "production", User: "production"}},
+		{Name: "default-kube-system", Context: KubeconfigContext{Cluster: "production", User: "production", Namespace: "kube-system"}},
+	}))
+
+	rest, err := config.RESTConfig("default-kube-system")
+	g.Expect(err).To(BeNil())
+	g.Expect(rest.Host).To(Equal("https://74.220.1.1:6443"))
+
+	// Renaming to the names it already has is fine, taking another entry's isn't
+	g.Expect(config.Rename("production")).To(Succeed())
+	err = config.Rename("default-kube-system")
+	g.Expect(err).To(MatchError("KubeconfigInvalidError: unable to rename context production to default-kube-system, another context already has that name"))
+
+	config.Clusters = append(config.Clusters, KubeconfigNamedCluster{Name: "staging"})
+	err = config.Rename("staging")
+	g.Expect(err).To(MatchError("KubeconfigInvalidError: unable to rename cluster production to staging, another cluster already has that name"))
+
+	config.Users = append(config.Users, KubeconfigNamedUser{Name: "admin"})
+	err = config.Rename("admin")
+	g.Expect(errors.Is(err, KubeconfigInvalidError)).To(BeTrue())
+	g.Expect(config.CurrentContext).To(Equal("production"))
+	g.Expect(config.Clusters[0].Name).To(Equal("production"))
+}
+
+func TestKubernetesClusterMergeKubeConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), ".kube", "config")
+	g.Expect(os.MkdirAll(filepath.Dir(path), 0o700)).To(Succeed())
+	g.Expect(os.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: kind
+  cluster:
+    server: https://127.0.0.1:35000
+contexts:
+- name: kind
+  context:
+    cluster: kind
+    user: kind
+    namespace: dev
+current-context: kind
+users:
+- name: kind
+  user:
+    token: abc
+`), 0o600)).To(Succeed())
+
+	cluster := &KubernetesCluster{Name: "production", KubeConfig: testKubeconfig}
+	g.Expect(cluster.MergeKubeConfig(path, nil)).To(Succeed())
+
+	merged, _ := os.ReadFile(path)
+	config, err := ParseKubeconfig(string(merged))
+	g.Expect(err).To(BeNil())
+	g.Expect(config.CurrentContext).To(Equal("kind"))
+	g.Expect(config.Contexts).To(HaveLen(2))
+	g.Expect(config.Contexts[0].Context.Namespace).To(Equal("dev"))
+	g.Expect(config.Contexts[1]).To(Equal(KubeconfigNamedContext{Name: "production", Context: KubeconfigContext{Cluster: "production", User: "production"}}))
+
+	// Merging again replaces the entries rather than duplicating them
+	g.Expect(cluster.MergeKubeConfig(path, &KubeconfigMergeOptions{Server: "10.0.0.5", SetCurrentContext: true})).To(Succeed())
+	merged, _ = os.ReadFile(path)
+	config, _ = ParseKubeconfig(string(merged))
+	g.Expect(config.CurrentContext).To(Equal("production"))
+	g.Expect(config.Clusters).To(HaveLen(2))
+	g.Expect(config.Users).To(HaveLen(2))
+
+	rest, err := config.RESTConfig("")
+	g.Expect(err).To(BeNil())
+	g.Expect(rest.Host).To(Equal("https://10.0.0.5:6443"))
+	rest, _ = config.RESTConfig("kind")
+	g.Expect(rest.BearerToken).To(Equal("abc"))
+
+	info, _ := os.Stat(path)
+	g.Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+}
+
+func TestDefaultKubeconfigPath(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Setenv("KUBECONFIG", "/tmp/a"+string(os.PathListSeparator)+"/tmp/b")
+	path, err := DefaultKubeconfigPath()
+	g.Expect(err).To(BeNil())
+	g.Expect(path).To(Equal("/tmp/a"))
+
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("HOME", "/home/civo")
+	path, _ = DefaultKubeconfigPath()
+	g.Expect(path).To(Equal("/home/civo/.kube/config"))
+
+	// A new file is created, along with its directory
+	dir := t.TempDir()
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "nested", "config"))
+	g.Expect((&KubernetesCluster{Name: "new", KubeConfig: testKubeconfig}).MergeKubeConfig("", nil)).To(Succeed())
+	data, err := os.ReadFile(filepath.Join(dir, "nested", "config"))
+	g.Expect(err).To(BeNil())
+	config, _ := ParseKubeconfig(string(data))
+	g.Expect(config.CurrentContext).To(Equal("new"))
+	g.Expect(config.Kind).To(Equal("Config"))
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -220,31 +219,10 @@ func (c *Client) FindKubernetesCluster(search string) (*KubernetesCluster, error
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := KubernetesCluster{}
-
-	for _, value := range clusters.Items {
-		if strings.EqualFold(value.Name, search) || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(strings.ToUpper(value.Name), strings.ToUpper(search)) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(clusters.Items, search, c.FindMode,
+		func(k KubernetesCluster) string { return k.ID },
+		func(k KubernetesCluster) []string { return []string{k.Name} },
+	)
 }
 
 // NewKubernetesClusters create a new cluster of kubernetes
@@ -367,29 +345,8 @@ func (c *Client) FindKubernetesClusterInstance(clusterID, search string) (*Insta
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Instance{}
-
-	for _, value := range instances {
-		if strings.EqualFold(value.Hostname, search) || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(strings.ToUpper(value.Hostname), strings.ToUpper(search)) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(instances, search, c.FindMode,
+		func(i Instance) string { return i.ID },
+		func(i Instance) []string { return []string{i.Hostname} },
+	)
 }
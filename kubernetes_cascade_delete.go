@@ -0,0 +1,246 @@
+package civogo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// KubernetesClusterCascadeOptions controls what DeleteKubernetesClusterCascade deletes
+type KubernetesClusterCascadeOptions struct {
+	// DryRun returns the plan without deleting anything
+	DryRun bool
+	// RetainAllVolumes keeps every volume, RetainVolumes keeps those with these IDs or names
+	RetainAllVolumes bool
+	RetainVolumes    []string
+	// RetainFirewall keeps the cluster's firewall
+	RetainFirewall bool
+}
+
+// KubernetesClusterDeletePlan is a cluster and the resources deleted with it
+type KubernetesClusterDeletePlan struct {
+	ClusterID   string
+	ClusterName string
+	// LoadBalancers are deleted first, as the cluster leaves them behind
+	LoadBalancers []LoadBalancer
+	// Volumes are deleted once the cluster has gone and RetainedVolumes are kept
+	Volumes         []Volume
+	RetainedVolumes []Volume
+	// Firewall is deleted last, it's nil if the cluster has none or it's being kept
+	Firewall *Firewall
+	// FirewallKept explains why the cluster's firewall isn't being deleted
+	FirewallKept string
+}
+
+// Steps describes the plan in the order it's applied
+func (p *KubernetesClusterDeletePlan) Steps() []string {
+	steps := []string{}
+	for _, lb := range p.LoadBalancers {
+		steps = append(steps, fmt.Sprintf("delete load balancer %s", lb.Name))
+	}
+	steps = append(steps, fmt.Sprintf("delete cluster %s and wait for it to go", p.ClusterName))
+	for _, volume := range p.Volumes {
+		steps = append(steps, fmt.Sprintf("delete volume %s", volume.Name))
+	}
+	for _, volume := range p.RetainedVolumes {
+		steps = append(steps, fmt.Sprintf("keep volume %s", volume.Name))
+	}
+	if p.Firewall != nil {
+		steps = append(steps, fmt.Sprintf("delete firewall %s", p.Firewall.Name))
+	} else if p.FirewallKept != "" {
+		steps = append(steps, "keep firewall, "+p.FirewallKept)
+	}
+	return steps
+}
+
+// KubernetesClusterCascadeDelete deletes clusters along with their load
+// balancers, volumes and firewall
+type KubernetesClusterCascadeDelete struct {
+	Client Clienter
+	KubernetesClusterCascadeOptions
+	// PollInterval is how often the cluster is checked while it's deleting, defaulting to 10 seconds
+	PollInterval time.Duration
+	// Timeout is how long to wait for the cluster to go, defaulting to 15 minutes
+	Timeout time.Duration
+	// Sleep and Now are used while waiting, when nil they're time.Sleep and time.Now
+	Sleep func(time.Duration)
+	Now   func() time.Time
+}
+
+// NewKubernetesClusterCascadeDelete returns a KubernetesClusterCascadeDelete using client with the default timings
+func NewKubernetesClusterCascadeDelete(client Clienter, options KubernetesClusterCascadeOptions) *KubernetesClusterCascadeDelete {
+	return &KubernetesClusterCascadeDelete{Client: client, KubernetesClusterCascadeOptions: options}
+}
+
+// DeleteKubernetesClusterCascade deletes the cluster with its dependent
+// resources using the default timings, see KubernetesClusterCascadeDelete.Delete
+func (c *Client) DeleteKubernetesClusterCascade(id string, options KubernetesClusterCascadeOptions) (*KubernetesClusterDeletePlan, error) {
+	return NewKubernetesClusterCascadeDelete(c, options).Delete(id)
+}
+
+// Plan finds the cluster's load balancers and volumes, those with its
+// ClusterID, and its firewall. The firewall is only deleted if nothing else uses it.
+// Delete adds any volumes left with the cluster's ID once it has gone.
+func (d *KubernetesClusterCascadeDelete) Plan(id string) (*KubernetesClusterDeletePlan, error) {
+	cluster, err := d.Client.GetKubernetesCluster(id)
+	if err != nil {
+		return nil, err
+	}
+	plan := &KubernetesClusterDeletePlan{
+		ClusterID:       cluster.ID,
+		ClusterName:     cluster.Name,
+		LoadBalancers:   []LoadBalancer{},
+		Volumes:         []Volume{},
+		RetainedVolumes: []Volume{},
+	}
+
+	lbs, err := d.Client.ListLoadBalancers()
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range lbs {
+		if lb.ClusterID == cluster.ID {
+			plan.LoadBalancers = append(plan.LoadBalancers, lb)
+		}
+	}
+
+	volumes, err := d.Client.ListVolumesForCluster(cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, volume := range volumes {
+		d.planVolume(plan, volume)
+	}
+
+	if cluster.FirewallID == "" {
+		return plan, nil
+	}
+	if d.RetainFirewall {
+		plan.FirewallKept = "it's being retained"
+		return plan, nil
+	}
+	firewalls, err := d.Client.ListFirewalls()
+	if err != nil {
+		return nil, err
+	}
+	for _, firewall := range firewalls {
+		if firewall.ID != cluster.FirewallID {
+			continue
+		}
+		switch {
+		case firewall.ClusterCount > 1:
+			plan.FirewallKept = fmt.Sprintf("%s is used by %d other clusters", firewall.Name, firewall.ClusterCount-1)
+		case firewall.InstanceCount > 0:
+			plan.FirewallKept = fmt.Sprintf("%s is used by %d instances", firewall.Name, firewall.InstanceCount)
+		default:
+			firewall := firewall
+			plan.Firewall = &firewall
+		}
+	}
+	return plan, nil
+}
+
+// Delete plans the deletion and, unless it's a dry run, applies it: the load
+// balancers, then the cluster, waiting for it to go, then the volumes, including
+// any left with the cluster's ID during teardown, and the firewall. It stops if a load balancer or the cluster can't be deleted, while
+// failures deleting volumes and the firewall are joined in the returned error.
+func (d *KubernetesClusterCascadeDelete) Delete(id string) (*KubernetesClusterDeletePlan, error) {
+	plan, err := d.Plan(id)
+	if err != nil || d.DryRun {
+		return plan, err
+	}
+
+	for _, lb := range plan.LoadBalancers {
+		if _, err := d.Client.DeleteLoadBalancer(lb.ID); err != nil {
+			return plan, fmt.Errorf("unable to delete load balancer %s: %w", lb.Name, err)
+		}
+	}
+
+	if _, err := d.Client.DeleteKubernetesCluster(plan.ClusterID); err != nil {
+		return plan, fmt.Errorf("unable to delete cluster %s: %w", plan.ClusterName, err)
+	}
+	if err := d.waitForCluster(plan); err != nil {
+		return plan, err
+	}
+
+	// Volumes can be released or created while the cluster is torn down
+	dangling, err := d.Client.ListDanglingVolumes()
+	if err != nil {
+		return plan, err
+	}
+	for _, volume := range dangling {
+		if volume.ClusterID == plan.ClusterID && !plannedVolume(plan, volume) {
+			d.planVolume(plan, volume)
+		}
+	}
+
+	errs := []error{}
+	for _, volume := range plan.Volumes {
+		if err := d.deleteVolume(volume); err != nil {
+			errs = append(errs, fmt.Errorf("unable to delete volume %s: %w", volume.Name, err))
+		}
+	}
+	if plan.Firewall != nil {
+		if _, err := d.Client.DeleteFirewall(plan.Firewall.ID); err != nil {
+			errs = append(errs, fmt.Errorf("unable to delete firewall %s: %w", plan.Firewall.Name, err))
+		}
+	}
+	return plan, errors.Join(errs...)
+}
+
+func (d *KubernetesClusterCascadeDelete) waitForCluster(plan *KubernetesClusterDeletePlan) error {
+	return d.poller().poll(func() (bool, error) {
+		_, err := d.Client.GetKubernetesCluster(plan.ClusterID)
+		if errors.Is(err, DatabaseKubernetesClusterNotFoundError) {
+			return true, nil
+		}
+		return false, err
+	}, func() error {
+		return fmt.Errorf("timed out waiting for cluster %s to be deleted", plan.ClusterName)
+	})
+}
+
+func (d *KubernetesClusterCascadeDelete) poller() poller {
+	return poller{
+		interval:        d.PollInterval,
+		timeout:         d.Timeout,
+		defaultInterval: 10 * time.Second,
+		defaultTimeout:  15 * time.Minute,
+		sleep:           d.Sleep,
+		now:             d.Now,
+	}
+}
+
+// planVolume adds the volume to the plan, to be deleted or retained
+func (d *KubernetesClusterCascadeDelete) planVolume(plan *KubernetesClusterDeletePlan, volume Volume) {
+	if d.RetainAllVolumes || findString(d.RetainVolumes, volume.ID) || findString(d.RetainVolumes, volume.Name) {
+		plan.RetainedVolumes = append(plan.RetainedVolumes, volume)
+		return
+	}
+	plan.Volumes = append(plan.Volumes, volume)
+}
+
+// plannedVolume returns true if the volume is already in the plan
+func plannedVolume(plan *KubernetesClusterDeletePlan, volume Volume) bool {
+	for _, planned := range append(append([]Volume{}, plan.Volumes...), plan.RetainedVolumes...) {
+		if planned.ID == volume.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteVolume detaches the volume if it's still attached, then deletes it
+func (d *KubernetesClusterCascadeDelete) deleteVolume(volume Volume) error {
+	current, err := d.Client.GetVolume(volume.ID)
+	if err != nil {
+		return err
+	}
+	if current.InstanceID != "" {
+		if _, err := d.Client.DetachVolume(volume.ID); err != nil {
+			return err
+		}
+	}
+	_, err = d.Client.DeleteVolume(volume.ID)
+	return err
+}
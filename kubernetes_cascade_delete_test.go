@@ -0,0 +1,102 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// releasingVolumeClient leaves a volume behind when a cluster is deleted, as the
+// CSI driver does when it releases a claim during teardown
+type releasingVolumeClient struct {
+	*FakeClient
+	volume Volume
+}
+
+func (c *releasingVolumeClient) DeleteKubernetesCluster(id string) (*SimpleResponse, error) {
+	c.Volumes = append(c.Volumes, c.volume)
+	return c.FakeClient.DeleteKubernetesCluster(id)
+}
+
+func TestDeleteKubernetesClusterCascade(t *testing.T) {
+	g := NewWithT(t)
+
+	fake, cluster := newFakeKubernetesCluster(&KubernetesClusterConfig{Name: "production", NumTargetNodes: 1, FirewallID: "fw-1"})
+	fake.DeletionDuration = 2 * time.Minute
+	fake.Firewalls = []Firewall{{ID: "fw-1", Name: "production", ClusterCount: 1}}
+	fake.LoadBalancers = []LoadBalancer{
+		{ID: "lb-1", Name: "ingress", ClusterID: cluster.ID},
+		{ID: "lb-2", Name: "standalone"},
+	}
+	fake.Volumes = []Volume{
+		{ID: "vol-1", Name: "pvc-data", ClusterID: cluster.ID, InstanceID: cluster.Instances[0].ID, Status: "attached"},
+		{ID: "vol-2", Name: "pvc-logs", ClusterID: cluster.ID, Status: "available"},
+		{ID: "vol-3", Name: "backups", Status: "available"},
+	}
+	client := &releasingVolumeClient{FakeClient: fake, volume: Volume{ID: "vol-4", Name: "pvc-cache", ClusterID: cluster.ID, Status: "available"}}
+
+	cascade := NewKubernetesClusterCascadeDelete(client, KubernetesClusterCascadeOptions{DryRun: true, RetainVolumes: []string{"pvc-logs"}})
+	cascade.PollInterval = time.Minute
+	cascade.Sleep = fake.Clock.Sleep
+	cascade.Now = fake.Clock.Now
+	plan, err := cascade.Delete(cluster.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(plan.Steps()).To(Equal([]string{
+		"delete load balancer ingress",
+		"delete cluster production and wait for it to go",
+		"delete volume pvc-data",
+		"keep volume pvc-logs",
+		"delete firewall production",
+	}))
+	g.Expect(fake.Clusters).To(HaveLen(1))
+	g.Expect(fake.LoadBalancers).To(HaveLen(2))
+
+	// The volume left behind during teardown is found and deleted too
+	cascade.DryRun = false
+	plan, err = cascade.Delete(cluster.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(plan.Steps()).To(ContainElement("delete volume pvc-cache"))
+	g.Expect(fake.Clusters).To(BeEmpty())
+	g.Expect(fake.Clock.Now()).To(Equal(time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC)))
+	g.Expect(fake.LoadBalancers).To(Equal([]LoadBalancer{{ID: "lb-2", Name: "standalone"}}))
+	g.Expect(fake.Firewalls).To(BeEmpty())
+
+	remaining := []string{}
+	for _, volume := range fake.Volumes {
+		remaining = append(remaining, volume.ID)
+	}
+	g.Expect(remaining).To(ConsistOf("vol-2", "vol-3"))
+}
+
+func TestDeleteKubernetesClusterCascadeKeepsSharedFirewall(t *testing.T) {
+	g := NewWithT(t)
+
+	client, cluster := newFakeKubernetesCluster(&KubernetesClusterConfig{Name: "production", NumTargetNodes: 1, FirewallID: "fw-1"})
+	client.DeletionDuration = 2 * time.Minute
+	client.Firewalls = []Firewall{{ID: "fw-1", Name: "production", ClusterCount: 1, InstanceCount: 2}}
+	client.Volumes = []Volume{{ID: "vol-1", Name: "pvc-data", ClusterID: cluster.ID, Status: "available"}}
+
+	cascade := &KubernetesClusterCascadeDelete{
+		Client:                          client,
+		KubernetesClusterCascadeOptions: KubernetesClusterCascadeOptions{RetainAllVolumes: true},
+		Timeout:                         time.Minute,
+		Sleep:                           client.Clock.Sleep,
+		Now:                             client.Clock.Now,
+	}
+	plan, err := cascade.Plan(cluster.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(plan.Firewall).To(BeNil())
+	g.Expect(plan.RetainedVolumes).To(HaveLen(1))
+	g.Expect(plan.Steps()[len(plan.Steps())-1]).To(Equal("keep firewall, production is used by 2 instances"))
+
+	cascade.RetainFirewall = true
+	plan, _ = cascade.Plan(cluster.ID)
+	g.Expect(plan.FirewallKept).To(Equal("it's being retained"))
+
+	_, err = cascade.Delete(cluster.ID)
+	g.Expect(errors.Is(err, TimeoutError)).To(BeTrue())
+	g.Expect(client.Volumes).To(HaveLen(1))
+	g.Expect(client.Firewalls).To(HaveLen(1))
+}
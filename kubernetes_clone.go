@@ -0,0 +1,257 @@
+package civogo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KubernetesClusterSpec is a portable description of a cluster, made by
+// ExportKubernetesCluster, which CloneKubernetesCluster can recreate in any region
+type KubernetesClusterSpec struct {
+	Name              string                        `json:"name"`
+	ClusterType       string                        `json:"cluster_type,omitempty"`
+	KubernetesVersion string                        `json:"kubernetes_version,omitempty"`
+	CNIPlugin         string                        `json:"cni_plugin,omitempty"`
+	Tags              []string                      `json:"tags,omitempty"`
+	Pools             []KubernetesClusterPoolConfig `json:"pools"`
+	// Network is nil for clusters on the region's default network
+	Network *KubernetesClusterSpecNetwork `json:"network,omitempty"`
+	// FirewallRules are the cluster firewall's rules, without their IDs
+	FirewallRules []FirewallRule                     `json:"firewall_rules,omitempty"`
+	Applications  []KubernetesClusterSpecApplication `json:"applications,omitempty"`
+}
+
+// KubernetesClusterSpecNetwork is the private network a cloned cluster is created in
+type KubernetesClusterSpecNetwork struct {
+	Label string `json:"label"`
+	CIDR  string `json:"cidr,omitempty"`
+}
+
+// KubernetesClusterSpecApplication is a marketplace application installed on the
+// cluster, or a default application which was removed from it
+type KubernetesClusterSpecApplication struct {
+	Name    string `json:"name"`
+	Plan    string `json:"plan,omitempty"`
+	Removed bool   `json:"removed,omitempty"`
+}
+
+// ExportKubernetesCluster describes the cluster so it can be cloned, see KubernetesClusterSpec
+func (c *Client) ExportKubernetesCluster(id string) (*KubernetesClusterSpec, error) {
+	return ExportKubernetesCluster(c, id)
+}
+
+// ExportKubernetesCluster describes the cluster with its pools, network,
+// firewall rules and installed applications, along with the marketplace's
+// default applications which aren't installed
+func ExportKubernetesCluster(client Clienter, id string) (*KubernetesClusterSpec, error) {
+	cluster, err := client.GetKubernetesCluster(id)
+	if err != nil {
+		return nil, err
+	}
+	spec := &KubernetesClusterSpec{
+		Name:              cluster.Name,
+		ClusterType:       cluster.ClusterType,
+		KubernetesVersion: kubernetesClusterVersion(cluster),
+		CNIPlugin:         cluster.CNIPlugin,
+		Tags:              cluster.Tags,
+		Pools:             []KubernetesClusterPoolConfig{},
+		FirewallRules:     []FirewallRule{},
+		Applications:      []KubernetesClusterSpecApplication{},
+	}
+
+	pools, err := client.ListKubernetesClusterPools(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range pools {
+		spec.Pools = append(spec.Pools, KubernetesClusterPoolConfig{
+			ID:               pool.ID,
+			Count:            pool.Count,
+			Size:             pool.Size,
+			Labels:           pool.Labels,
+			Taints:           pool.Taints,
+			PublicIPNodePool: pool.PublicIPNodePool,
+		})
+	}
+
+	if cluster.NetworkID != "" {
+		networks, err := client.ListNetworks()
+		if err != nil {
+			return nil, err
+		}
+		for _, network := range networks {
+			if network.ID == cluster.NetworkID && !network.Default {
+				spec.Network = &KubernetesClusterSpecNetwork{Label: network.Label, CIDR: network.CIDR}
+			}
+		}
+	}
+
+	if cluster.FirewallID != "" {
+		rules, err := client.ListFirewallRules(cluster.FirewallID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range rules {
+			rule.ID, rule.FirewallID = "", ""
+			spec.FirewallRules = append(spec.FirewallRules, rule)
+		}
+	}
+
+	installed := map[string]bool{}
+	for _, application := range cluster.InstalledApplications {
+		name := installedApplicationName(application)
+		installed[strings.ToLower(name)] = true
+		spec.Applications = append(spec.Applications, KubernetesClusterSpecApplication{Name: name, Plan: application.Plan})
+	}
+
+	marketplace, err := client.ListKubernetesMarketplaceApplications()
+	if err != nil {
+		return nil, err
+	}
+	for _, application := range marketplace {
+		if application.Default && !installed[strings.ToLower(application.Name)] {
+			spec.Applications = append(spec.Applications, KubernetesClusterSpecApplication{Name: application.Name, Removed: true})
+		}
+	}
+	return spec, nil
+}
+
+// CloneKubernetesCluster creates the cluster described by spec in region, or
+// in the client's region if it's empty. See CloneKubernetesCluster.
+func (c *Client) CloneKubernetesCluster(spec *KubernetesClusterSpec, region string) (*KubernetesCluster, error) {
+	target := c.clone()
+	if region != "" {
+		target.Region = region
+	}
+	return CloneKubernetesCluster(target, spec, target.Region)
+}
+
+// CloneKubernetesCluster creates the spec's network and a firewall with its
+// rules, then the cluster with its pools and applications. If a step fails the
+// network and firewall it created are deleted again. The client must be for
+// region, which is passed on to the API calls that take one. To clone a cluster
+// into its own region give the spec a new Name first, nothing is created if a
+// cluster called Name or a firewall called Name+"-firewall" already exists.
+func CloneKubernetesCluster(client Clienter, spec *KubernetesClusterSpec, region string) (cluster *KubernetesCluster, err error) {
+	if len(spec.Pools) == 0 {
+		err := fmt.Errorf("cluster %s has no pools", spec.Name)
+		return nil, KubernetesClusterConfigInvalidError.wrap(err)
+	}
+	if err := checkKubernetesCloneName(client, spec.Name, region); err != nil {
+		return nil, err
+	}
+
+	cleanup := []func() error{}
+	defer func() {
+		if err == nil {
+			return
+		}
+		errs := []error{err}
+		for i := len(cleanup) - 1; i >= 0; i-- {
+			errs = append(errs, cleanup[i]())
+		}
+		err = errors.Join(errs...)
+	}()
+
+	networkID := ""
+	if spec.Network != nil {
+		network, err := client.CreateNetwork(NetworkConfig{Label: spec.Network.Label, CIDRv4: spec.Network.CIDR, Region: region})
+		if err != nil {
+			return nil, fmt.Errorf("unable to create network %s: %w", spec.Network.Label, err)
+		}
+		networkID = network.ID
+		cleanup = append(cleanup, func() error {
+			_, err := client.DeleteNetwork(networkID)
+			return err
+		})
+	}
+
+	createRules := false
+	firewall, err := client.NewFirewall(&FirewallConfig{Name: spec.Name + "-firewall", Region: region, NetworkID: networkID, CreateRules: &createRules})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create firewall for cluster %s: %w", spec.Name, err)
+	}
+	cleanup = append(cleanup, func() error {
+		_, err := client.DeleteFirewall(firewall.ID)
+		return err
+	})
+	for _, rule := range spec.FirewallRules {
+		_, err := client.NewFirewallRule(&FirewallRuleConfig{
+			FirewallID: firewall.ID,
+			Region:     region,
+			Protocol:   rule.Protocol,
+			StartPort:  rule.StartPort,
+			EndPort:    rule.EndPort,
+			Cidr:       rule.Cidr,
+			Direction:  rule.Direction,
+			Action:     rule.Action,
+			Label:      rule.Label,
+			Ports:      rule.Ports,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to create firewall rule %s: %w", rule.Label, err)
+		}
+	}
+
+	applications := []string{}
+	for _, application := range spec.Applications {
+		entry := application.Name
+		if application.Removed {
+			entry = "-" + entry
+		} else if application.Plan != "" {
+			entry += ":" + application.Plan
+		}
+		applications = append(applications, entry)
+	}
+	pools := []KubernetesClusterPoolConfig{}
+	for _, pool := range spec.Pools {
+		pool.Region = region
+		pools = append(pools, pool)
+	}
+
+	config := &KubernetesClusterConfig{
+		Name:              spec.Name,
+		Region:            region,
+		ClusterType:       spec.ClusterType,
+		KubernetesVersion: spec.KubernetesVersion,
+		CNIPlugin:         spec.CNIPlugin,
+		NetworkID:         networkID,
+		FirewallID:        firewall.ID,
+		Tags:              strings.Join(spec.Tags, " "),
+		Pools:             pools,
+		Applications:      strings.Join(applications, ","),
+	}
+	cluster, err = client.NewKubernetesClusters(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create cluster %s: %w", spec.Name, err)
+	}
+	return cluster, nil
+}
+
+// checkKubernetesCloneName returns an error if a cluster called name or its
+// firewall already exist in region
+func checkKubernetesCloneName(client Clienter, name, region string) error {
+	clusters, err := client.ListKubernetesClusters()
+	if err != nil {
+		return err
+	}
+	for _, cluster := range clusters.Items {
+		if cluster.Name == name {
+			err := fmt.Errorf("a cluster called %s already exists in %s, give the clone a new name", name, region)
+			return DatabaseKubernetesClusterDuplicateError.wrap(err)
+		}
+	}
+
+	firewalls, err := client.ListFirewalls()
+	if err != nil {
+		return err
+	}
+	for _, firewall := range firewalls {
+		if firewall.Name == name+"-firewall" {
+			err := fmt.Errorf("a firewall called %s already exists in %s, give the clone a new name", firewall.Name, region)
+			return DatabaseFirewallDuplicateNameError.wrap(err)
+		}
+	}
+	return nil
+}
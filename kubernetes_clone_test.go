@@ -0,0 +1,117 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestExportAndCloneKubernetesCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.Networks = []Network{{ID: "net-1", Default: true}, {ID: "net-2", Label: "prod-net", CIDR: "10.20.0.0/24"}}
+	client.Firewalls = []Firewall{{ID: "fw-1", Name: "production", NetworkID: "net-2"}}
+	client.FirewallRules = []FirewallRule{
+		{ID: "rule-1", FirewallID: "fw-1", Protocol: "tcp", StartPort: "6443", EndPort: "6443", Cidr: []string{"0.0.0.0/0"}, Direction: "ingress", Action: "allow", Label: "api"},
+		{ID: "rule-2", FirewallID: "fw-other", Protocol: "tcp", StartPort: "22", EndPort: "22", Label: "ssh"},
+	}
+	client.MarketplaceApplications = []KubernetesMarketplaceApplication{
+		{Name: "longhorn", Version: "1.6.0"},
+		{Name: "Traefik-v2-nodeport", Version: "2.10.0", Default: true},
+	}
+	cluster, _ := client.NewKubernetesClusters(&KubernetesClusterConfig{
+		Name:              "production",
+		ClusterType:       "k3s",
+		KubernetesVersion: "1.28.7-k3s1",
+		CNIPlugin:         "cilium",
+		NetworkID:         "net-2",
+		FirewallID:        "fw-1",
+		Tags:              "team:web env:prod",
+		Applications:      "longhorn:5GB",
+		Pools: []KubernetesClusterPoolConfig{{
+			ID:     "workers",
+			Count:  2,
+			Size:   "g4s.kube.medium",
+			Labels: map[string]string{"tier": "web"},
+			Taints: []corev1.Taint{{Key: "dedicated", Value: "web", Effect: corev1.TaintEffectNoSchedule}},
+		}},
+	})
+
+	spec, err := ExportKubernetesCluster(client, cluster.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(spec).To(Equal(&KubernetesClusterSpec{
+		Name:              "production",
+		ClusterType:       "k3s",
+		KubernetesVersion: "1.28.7-k3s1",
+		CNIPlugin:         "cilium",
+		Tags:              []string{"team:web", "env:prod"},
+		Pools: []KubernetesClusterPoolConfig{{
+			ID:     "workers",
+			Count:  2,
+			Size:   "g4s.kube.medium",
+			Labels: map[string]string{"tier": "web"},
+			Taints: []corev1.Taint{{Key: "dedicated", Value: "web", Effect: corev1.TaintEffectNoSchedule}},
+		}},
+		Network: &KubernetesClusterSpecNetwork{Label: "prod-net", CIDR: "10.20.0.0/24"},
+		FirewallRules: []FirewallRule{
+			{Protocol: "tcp", StartPort: "6443", EndPort: "6443", Cidr: []string{"0.0.0.0/0"}, Direction: "ingress", Action: "allow", Label: "api"},
+		},
+		Applications: []KubernetesClusterSpecApplication{
+			{Name: "longhorn", Plan: "5GB"},
+			{Name: "Traefik-v2-nodeport", Removed: true},
+		},
+	}))
+
+	// A clone with the same name collides with the original
+	_, err = CloneKubernetesCluster(client, spec, "LON1")
+	g.Expect(errors.Is(err, DatabaseKubernetesClusterDuplicateError)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("a cluster called production already exists in LON1, give the clone a new name"))
+	g.Expect(client.Networks).To(HaveLen(2))
+	g.Expect(client.Firewalls).To(HaveLen(1))
+
+	spec.Name = "production-fra1"
+	clone, err := CloneKubernetesCluster(client, spec, "FRA1")
+	g.Expect(err).To(BeNil())
+	g.Expect(clone.Name).To(Equal("production-fra1"))
+	g.Expect(clone.NetworkID).NotTo(Equal("net-2"))
+	g.Expect(clone.FirewallID).NotTo(Equal("fw-1"))
+
+	cloned, err := ExportKubernetesCluster(client, clone.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(cloned).To(Equal(spec))
+
+	firewall, _ := client.FindFirewall(clone.FirewallID)
+	g.Expect(firewall.Name).To(Equal("production-fra1-firewall"))
+	g.Expect(firewall.NetworkID).To(Equal(clone.NetworkID))
+
+	// So does one whose firewall name is taken
+	client.Clusters = client.Clusters[:1]
+	_, err = CloneKubernetesCluster(client, spec, "FRA1")
+	g.Expect(errors.Is(err, DatabaseFirewallDuplicateNameError)).To(BeTrue())
+}
+
+func TestCloneKubernetesClusterCleansUpOnFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.Networks = []Network{{ID: "net-1", Default: true}}
+	spec := &KubernetesClusterSpec{
+		Name:    "production",
+		Pools:   []KubernetesClusterPoolConfig{{ID: "workers", Count: 2}, {ID: "workers", Count: 2}},
+		Network: &KubernetesClusterSpecNetwork{Label: "prod-net"},
+	}
+
+	_, err := CloneKubernetesCluster(client, spec, "FRA1")
+	g.Expect(errors.Is(err, DatabaseKubernetesClusterDuplicateError)).To(BeTrue())
+	g.Expect(err.Error()).To(HavePrefix("unable to create cluster production: "))
+	g.Expect(client.Networks).To(HaveLen(1))
+	g.Expect(client.Firewalls).To(BeEmpty())
+	g.Expect(client.Clusters).To(BeEmpty())
+
+	spec.Pools = nil
+	_, err = CloneKubernetesCluster(client, spec, "FRA1")
+	g.Expect(err).To(MatchError("KubernetesClusterConfigInvalidError: cluster production has no pools"))
+}
@@ -0,0 +1,201 @@
+package civogo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// KubernetesClusterTypes are the values accepted for KubernetesClusterConfig.ClusterType
+var KubernetesClusterTypes = []string{"k3s", "talos"}
+
+// KubernetesCNIPlugins are the values accepted for KubernetesClusterConfig.CNIPlugin
+var KubernetesCNIPlugins = []string{"flannel", "cilium"}
+
+// Validate checks the config before it's sent to NewKubernetesClusters,
+// returning every problem at once as a KubernetesClusterConfigInvalidError.
+// When client is set the node sizes, version, network and firewall are also
+// checked against the account, a nil client only checks the config itself.
+func (kc *KubernetesClusterConfig) Validate(client Clienter) error {
+	problems := kc.validateFields()
+
+	if client != nil {
+		remote, err := kc.validateWithClient(client)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, remote...)
+	}
+
+	if len(problems) > 0 {
+		return KubernetesClusterConfigInvalidError.wrap(fmt.Errorf("%s", strings.Join(problems, ", ")))
+	}
+	return nil
+}
+
+func (kc *KubernetesClusterConfig) validateFields() []string {
+	problems := []string{}
+	switch {
+	case kc.Name == "":
+		problems = append(problems, "name is required")
+	case len(validation.IsDNS1123Label(kc.Name)) > 0:
+		problems = append(problems, fmt.Sprintf("name %q must be at most 63 lowercase letters, digits and hyphens, starting and ending with a letter or digit", kc.Name))
+	}
+
+	if kc.ClusterType != "" && !findString(KubernetesClusterTypes, kc.ClusterType) {
+		problems = append(problems, fmt.Sprintf("cluster type %q must be one of %s", kc.ClusterType, strings.Join(KubernetesClusterTypes, ", ")))
+	}
+	if kc.CNIPlugin != "" && !findString(KubernetesCNIPlugins, kc.CNIPlugin) {
+		problems = append(problems, fmt.Sprintf("CNI plugin %q must be one of %s", kc.CNIPlugin, strings.Join(KubernetesCNIPlugins, ", ")))
+	}
+	if kc.NumTargetNodes < 0 {
+		problems = append(problems, "number of target nodes can't be negative")
+	}
+
+	seen := map[string]bool{}
+	for i, pool := range kc.Pools {
+		name := fmt.Sprintf("pool %d", i+1)
+		if pool.ID != "" {
+			name = "pool " + pool.ID
+			if seen[pool.ID] {
+				problems = append(problems, fmt.Sprintf("%s is listed more than once", name))
+			}
+			seen[pool.ID] = true
+		}
+		if pool.Count < 1 {
+			problems = append(problems, fmt.Sprintf("%s needs at least one node", name))
+		}
+		if pool.Size == "" {
+			problems = append(problems, fmt.Sprintf("%s has no size", name))
+		}
+		keys := []string{}
+		for key := range pool.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if value := pool.Labels[key]; len(validation.IsQualifiedName(key)) > 0 || len(validation.IsValidLabelValue(value)) > 0 {
+				problems = append(problems, fmt.Sprintf("%s has an invalid label %s=%s", name, key, value))
+			}
+		}
+		for _, taint := range pool.Taints {
+			if problem := validateKubernetesTaint(taint); problem != "" {
+				problems = append(problems, fmt.Sprintf("%s has an invalid taint %s: %s", name, taint.ToString(), problem))
+			}
+		}
+	}
+	return problems
+}
+
+func validateKubernetesTaint(taint corev1.Taint) string {
+	switch {
+	case len(validation.IsQualifiedName(taint.Key)) > 0:
+		return "the key must be a qualified name such as example.com/dedicated"
+	case taint.Value != "" && len(validation.IsValidLabelValue(taint.Value)) > 0:
+		return "the value must be at most 63 letters, digits, '-', '_' or '.'"
+	}
+	switch taint.Effect {
+	case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		return ""
+	}
+	return "the effect must be NoSchedule, PreferNoSchedule or NoExecute"
+}
+
+// validateWithClient checks the parts of the config which refer to the
+// account, returning an error only if the API calls fail
+func (kc *KubernetesClusterConfig) validateWithClient(client Clienter) ([]string, error) {
+	problems := []string{}
+
+	sizes := []string{}
+	if kc.TargetNodesSize != "" {
+		sizes = append(sizes, kc.TargetNodesSize)
+	}
+	for _, pool := range kc.Pools {
+		if pool.Size != "" && !findString(sizes, pool.Size) {
+			sizes = append(sizes, pool.Size)
+		}
+	}
+	if len(sizes) > 0 {
+		available, err := client.ListInstanceSizes()
+		if err != nil {
+			return nil, err
+		}
+		kubernetesSizes := []string{}
+		for _, size := range available {
+			if strings.EqualFold(size.Type, "kubernetes") && size.Selectable {
+				kubernetesSizes = append(kubernetesSizes, size.Name)
+			}
+		}
+		for _, size := range sizes {
+			if !findString(kubernetesSizes, size) {
+				problems = append(problems, fmt.Sprintf("size %s isn't an available Kubernetes node size", size))
+			}
+		}
+	}
+
+	if kc.KubernetesVersion != "" {
+		versions, err := client.ListAvailableKubernetesVersions()
+		if err != nil {
+			return nil, err
+		}
+		var version *KubernetesVersion
+		for i := range versions {
+			if versions[i].Version == kc.KubernetesVersion {
+				version = &versions[i]
+			}
+		}
+		switch {
+		case version == nil:
+			problems = append(problems, fmt.Sprintf("version %s isn't available", kc.KubernetesVersion))
+		case kc.ClusterType != "" && version.ClusterType != "" && version.ClusterType != kc.ClusterType:
+			problems = append(problems, fmt.Sprintf("version %s is for %s clusters, not %s", kc.KubernetesVersion, version.ClusterType, kc.ClusterType))
+		}
+	}
+
+	if kc.NetworkID == "" && kc.FirewallID == "" {
+		return problems, nil
+	}
+	networks, err := client.ListNetworks()
+	if err != nil {
+		return nil, err
+	}
+	networkID := kc.NetworkID
+	if networkID == "" {
+		for _, network := range networks {
+			if network.Default {
+				networkID = network.ID
+			}
+		}
+	} else {
+		found := false
+		for _, network := range networks {
+			found = found || network.ID == networkID
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("network %s doesn't exist", networkID))
+		}
+	}
+
+	if kc.FirewallID != "" {
+		firewalls, err := client.ListFirewalls()
+		if err != nil {
+			return nil, err
+		}
+		var firewall *Firewall
+		for i := range firewalls {
+			if firewalls[i].ID == kc.FirewallID {
+				firewall = &firewalls[i]
+			}
+		}
+		switch {
+		case firewall == nil:
+			problems = append(problems, fmt.Sprintf("firewall %s doesn't exist", kc.FirewallID))
+		case firewall.NetworkID != "" && networkID != "" && firewall.NetworkID != networkID:
+			problems = append(problems, fmt.Sprintf("firewall %s is in network %s, not the cluster's network %s", firewall.Name, firewall.NetworkID, networkID))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,93 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestKubernetesClusterConfigValidate(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.InstanceSizes = []InstanceSize{
+		{Name: "g4s.kube.medium", Type: "Kubernetes", Selectable: true},
+		{Name: "g4s.kube.legacy", Type: "Kubernetes"},
+		{Name: "g3.medium", Type: "Instance", Selectable: true},
+	}
+	client.KubernetesVersions = []KubernetesVersion{
+		{Version: "1.28.7-k3s1", ClusterType: "k3s"},
+		{Version: "1.28.2-talos", ClusterType: "talos"},
+	}
+	client.Networks = []Network{{ID: "net-1", Default: true}, {ID: "net-2", Label: "prod-net"}}
+	client.Firewalls = []Firewall{{ID: "fw-1", Name: "default", NetworkID: "net-1"}, {ID: "fw-2", Name: "prod", NetworkID: "net-2"}}
+
+	tests := map[string]struct {
+		config *KubernetesClusterConfig
+		err    string
+	}{
+		"valid": {config: &KubernetesClusterConfig{
+			Name:              "production",
+			ClusterType:       "k3s",
+			KubernetesVersion: "1.28.7-k3s1",
+			CNIPlugin:         "cilium",
+			NetworkID:         "net-2",
+			FirewallID:        "fw-2",
+			Pools: []KubernetesClusterPoolConfig{{
+				ID:     "workers",
+				Count:  3,
+				Size:   "g4s.kube.medium",
+				Labels: map[string]string{"example.com/tier": "web"},
+				Taints: []corev1.Taint{{Key: "dedicated", Value: "web", Effect: corev1.TaintEffectNoSchedule}},
+			}},
+		}},
+		// The default network is used when there's no network ID
+		"default network": {config: &KubernetesClusterConfig{Name: "production", FirewallID: "fw-1"}},
+		"missing network": {
+			config: &KubernetesClusterConfig{Name: "production", NetworkID: "missing"},
+			err:    "network missing doesn't exist",
+		},
+		"every problem": {
+			config: &KubernetesClusterConfig{
+				Name:              "Production_1",
+				ClusterType:       "talos",
+				KubernetesVersion: "1.28.7-k3s1",
+				CNIPlugin:         "calico",
+				TargetNodesSize:   "g3.medium",
+				FirewallID:        "fw-2",
+				Pools: []KubernetesClusterPoolConfig{
+					{ID: "workers", Count: 0, Size: "g4s.kube.legacy", Labels: map[string]string{"tier": "web app"}},
+					{ID: "workers", Count: 1, Size: "g4s.kube.medium", Taints: []corev1.Taint{
+						{Key: "-bad", Effect: corev1.TaintEffectNoSchedule},
+						{Key: "dedicated", Value: "web", Effect: "Never"},
+					}},
+				},
+			},
+			err: `name "Production_1" must be at most 63 lowercase letters, digits and hyphens, starting and ending with a letter or digit, ` +
+				`CNI plugin "calico" must be one of flannel, cilium, ` +
+				"pool workers needs at least one node, " +
+				"pool workers has an invalid label tier=web app, " +
+				"pool workers is listed more than once, " +
+				"pool workers has an invalid taint -bad:NoSchedule: the key must be a qualified name such as example.com/dedicated, " +
+				"pool workers has an invalid taint dedicated=web:Never: the effect must be NoSchedule, PreferNoSchedule or NoExecute, " +
+				"size g3.medium isn't an available Kubernetes node size, " +
+				"size g4s.kube.legacy isn't an available Kubernetes node size, " +
+				"version 1.28.7-k3s1 is for k3s clusters, not talos, " +
+				"firewall prod is in network net-2, not the cluster's network net-1",
+		},
+	}
+	for name, test := range tests {
+		err := test.config.Validate(client)
+		if test.err == "" {
+			g.Expect(err).To(BeNil(), name)
+			continue
+		}
+		g.Expect(errors.Is(err, KubernetesClusterConfigInvalidError)).To(BeTrue(), name)
+		g.Expect(err).To(MatchError("KubernetesClusterConfigInvalidError: "+test.err), name)
+	}
+
+	// Without a client only the config itself is checked
+	g.Expect(tests["missing network"].config.Validate(nil)).To(Succeed())
+}
@@ -0,0 +1,177 @@
+package civogo
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types reported in KubernetesCluster.Conditions
+const (
+	KubernetesConditionControlPlaneReady  = "ControlPlaneReady"
+	KubernetesConditionWorkerNodesReady   = "WorkerNodesReady"
+	KubernetesConditionClusterVersionSync = "ClusterVersionSync"
+)
+
+// KubernetesConditionTypes lists the known condition types in the order they're reported
+var KubernetesConditionTypes = []string{
+	KubernetesConditionControlPlaneReady,
+	KubernetesConditionWorkerNodesReady,
+	KubernetesConditionClusterVersionSync,
+}
+
+// KubernetesClusterHealth summarises a cluster's conditions
+type KubernetesClusterHealth string
+
+// Health values returned by KubernetesCluster.Health
+const (
+	// KubernetesClusterHealthy means every condition is true and synced
+	KubernetesClusterHealthy KubernetesClusterHealth = "Healthy"
+	// KubernetesClusterProgressing means a condition isn't true yet but the cluster is changing
+	KubernetesClusterProgressing KubernetesClusterHealth = "Progressing"
+	// KubernetesClusterDegraded means a condition is false while the cluster isn't changing
+	KubernetesClusterDegraded KubernetesClusterHealth = "Degraded"
+	// KubernetesClusterHealthUnknown means there are no conditions, or one is Unknown
+	KubernetesClusterHealthUnknown KubernetesClusterHealth = "Unknown"
+)
+
+// KubernetesClusterHealthSummary is a cluster's health with the conditions behind it
+type KubernetesClusterHealthSummary struct {
+	Health KubernetesClusterHealth
+	// NotReady holds the types of the conditions which aren't true and synced
+	NotReady []string
+	Message  string
+}
+
+// ConditionTransition is a change to a condition between two polls. From is
+// empty for a new condition and To is empty for one which has gone.
+type ConditionTransition struct {
+	Type    string
+	From    metav1.ConditionStatus
+	To      metav1.ConditionStatus
+	Reason  string
+	Message string
+	// LastTransitionTime is when the condition changed, if the API reported it
+	LastTransitionTime metav1.Time
+}
+
+// String describes the transition, such as "WorkerNodesReady: False -> True"
+func (t ConditionTransition) String() string {
+	from, to := string(t.From), string(t.To)
+	if from == "" {
+		from = "(none)"
+	}
+	if to == "" {
+		to = "(none)"
+	}
+	description := fmt.Sprintf("%s: %s -> %s", t.Type, from, to)
+	if t.Reason != "" {
+		description += " (" + t.Reason + ")"
+	}
+	return description
+}
+
+// FindCondition returns the condition with the given type, or nil if there isn't one
+func FindCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// FindCondition returns the cluster's condition with the given type, or nil if there isn't one
+func (k *KubernetesCluster) FindCondition(conditionType string) *Condition {
+	return FindCondition(k.Conditions, conditionType)
+}
+
+// IsConditionTrue returns true if the cluster has the condition and it's True
+func (k *KubernetesCluster) IsConditionTrue(conditionType string) bool {
+	condition := k.FindCondition(conditionType)
+	return condition != nil && condition.Status == metav1.ConditionTrue
+}
+
+// Health summarises the cluster's conditions, see HealthSummary
+func (k *KubernetesCluster) Health() KubernetesClusterHealth {
+	return k.HealthSummary().Health
+}
+
+// HealthSummary summarises the cluster's conditions. A condition which isn't
+// true makes the cluster Progressing while it's building, upgrading or not
+// synced, and Degraded otherwise.
+func (k *KubernetesCluster) HealthSummary() KubernetesClusterHealthSummary {
+	summary := KubernetesClusterHealthSummary{NotReady: []string{}}
+	if len(k.Conditions) == 0 {
+		summary.Health = KubernetesClusterHealthUnknown
+		summary.Message = "the cluster hasn't reported any conditions"
+		return summary
+	}
+
+	unknown, changing := false, k.Status != "" && k.Status != "ACTIVE"
+	reasons := []string{}
+	for _, condition := range k.Conditions {
+		if condition.Status == metav1.ConditionTrue && condition.Synced {
+			continue
+		}
+		summary.NotReady = append(summary.NotReady, condition.Type)
+		reason := fmt.Sprintf("%s is %s", condition.Type, condition.Status)
+		if condition.Message != "" {
+			reason += ": " + condition.Message
+		}
+		reasons = append(reasons, reason)
+
+		switch {
+		case condition.Status == metav1.ConditionUnknown:
+			unknown = true
+		case !condition.Synced:
+			changing = true
+		}
+	}
+
+	switch {
+	case len(summary.NotReady) == 0:
+		summary.Health = KubernetesClusterHealthy
+		summary.Message = "all conditions are true"
+		return summary
+	case unknown:
+		summary.Health = KubernetesClusterHealthUnknown
+	case changing:
+		summary.Health = KubernetesClusterProgressing
+	default:
+		summary.Health = KubernetesClusterDegraded
+	}
+	summary.Message = strings.Join(reasons, ", ")
+	return summary
+}
+
+// DiffConditions returns the conditions whose status changed between before
+// and after, including ones which were added or removed, in after's order
+// followed by the removed ones
+func DiffConditions(before, after []Condition) []ConditionTransition {
+	transitions := []ConditionTransition{}
+	for _, condition := range after {
+		var from metav1.ConditionStatus
+		if previous := FindCondition(before, condition.Type); previous != nil {
+			from = previous.Status
+		}
+		if from == condition.Status {
+			continue
+		}
+		transitions = append(transitions, ConditionTransition{
+			Type:               condition.Type,
+			From:               from,
+			To:                 condition.Status,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
+	}
+	for _, condition := range before {
+		if FindCondition(after, condition.Type) == nil {
+			transitions = append(transitions, ConditionTransition{Type: condition.Type, From: condition.Status})
+		}
+	}
+	return transitions
+}
@@ -0,0 +1,94 @@
+package civogo
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestKubernetesClusterConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &KubernetesCluster{Status: "ACTIVE", Conditions: []Condition{
+		{Type: KubernetesConditionControlPlaneReady, Status: metav1.ConditionTrue, Synced: true},
+		{Type: KubernetesConditionWorkerNodesReady, Status: metav1.ConditionFalse, Synced: true, Message: "1 of 3 nodes ready"},
+	}}
+
+	g.Expect(cluster.IsConditionTrue(KubernetesConditionControlPlaneReady)).To(BeTrue())
+	g.Expect(cluster.IsConditionTrue(KubernetesConditionWorkerNodesReady)).To(BeFalse())
+	g.Expect(cluster.IsConditionTrue(KubernetesConditionClusterVersionSync)).To(BeFalse())
+	g.Expect(cluster.FindCondition(KubernetesConditionWorkerNodesReady).Message).To(Equal("1 of 3 nodes ready"))
+	g.Expect(cluster.FindCondition("Missing")).To(BeNil())
+
+	g.Expect(cluster.HealthSummary()).To(Equal(KubernetesClusterHealthSummary{
+		Health:   KubernetesClusterDegraded,
+		NotReady: []string{KubernetesConditionWorkerNodesReady},
+		Message:  "WorkerNodesReady is False: 1 of 3 nodes ready",
+	}))
+
+	cluster.Status = "BUILDING"
+	g.Expect(cluster.Health()).To(Equal(KubernetesClusterProgressing))
+
+	cluster.Status = "ACTIVE"
+	cluster.Conditions[1].Synced = false
+	g.Expect(cluster.Health()).To(Equal(KubernetesClusterProgressing))
+
+	cluster.Conditions[1].Status = metav1.ConditionUnknown
+	g.Expect(cluster.Health()).To(Equal(KubernetesClusterHealthUnknown))
+
+	cluster.Conditions[1] = Condition{Type: KubernetesConditionWorkerNodesReady, Status: metav1.ConditionTrue, Synced: true}
+	g.Expect(cluster.Health()).To(Equal(KubernetesClusterHealthy))
+
+	g.Expect((&KubernetesCluster{}).Health()).To(Equal(KubernetesClusterHealthUnknown))
+}
+
+func TestKubernetesClusterHealthFromFakeClient(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.Clock = NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client.ClusterBuildDuration = time.Minute
+
+	cluster, _ := client.NewKubernetesClusters(&KubernetesClusterConfig{Name: "production"})
+	before, _ := client.GetKubernetesCluster(cluster.ID)
+	g.Expect(before.Health()).To(Equal(KubernetesClusterProgressing))
+	g.Expect(before.HealthSummary().NotReady).To(Equal(KubernetesConditionTypes))
+
+	client.Clock.Advance(time.Minute)
+	after, _ := client.GetKubernetesCluster(cluster.ID)
+	g.Expect(after.Health()).To(Equal(KubernetesClusterHealthy))
+
+	transitions := DiffConditions(before.Conditions, after.Conditions)
+	g.Expect(transitions).To(HaveLen(3))
+	g.Expect(transitions[0].String()).To(Equal("ControlPlaneReady: False -> True"))
+	g.Expect(transitions[0].LastTransitionTime.Time).To(Equal(client.Clock.Now()))
+}
+
+func TestDiffConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	before := []Condition{
+		{Type: KubernetesConditionControlPlaneReady, Status: metav1.ConditionTrue},
+		{Type: KubernetesConditionWorkerNodesReady, Status: metav1.ConditionTrue},
+		{Type: "Legacy", Status: metav1.ConditionTrue},
+	}
+	after := []Condition{
+		{Type: KubernetesConditionControlPlaneReady, Status: metav1.ConditionTrue},
+		{Type: KubernetesConditionWorkerNodesReady, Status: metav1.ConditionFalse, Reason: "NodeNotReady"},
+		{Type: KubernetesConditionClusterVersionSync, Status: metav1.ConditionTrue},
+	}
+
+	transitions := DiffConditions(before, after)
+	descriptions := []string{}
+	for _, transition := range transitions {
+		descriptions = append(descriptions, transition.String())
+	}
+	g.Expect(descriptions).To(Equal([]string{
+		"WorkerNodesReady: True -> False (NodeNotReady)",
+		"ClusterVersionSync: (none) -> True",
+		"Legacy: True -> (none)",
+	}))
+	g.Expect(DiffConditions(after, after)).To(BeEmpty())
+}
@@ -0,0 +1,332 @@
+package civogo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KubernetesApplicationInstall is a marketplace application to install on a cluster
+type KubernetesApplicationInstall struct {
+	Name string
+	// Plan is the label of one of the application's plans. It can be left empty
+	// if the application has a single plan, or if Configuration picks one.
+	Plan string
+	// Configuration are values the plan must set, such as {"VOLUME_SIZE": "5Gi"}.
+	// The API only takes a plan, so these choose and check it.
+	Configuration map[string]string
+}
+
+// KubernetesApplicationPlan is the applications to install and uninstall on a
+// cluster, with dependencies resolved
+type KubernetesApplicationPlan struct {
+	ClusterID string
+	// Install lists the applications in install order, dependencies first
+	Install []KubernetesApplicationInstall
+	// Uninstall lists the names of the applications to remove
+	Uninstall []string
+	// AlreadyInstalled lists requested applications and dependencies which the cluster has
+	AlreadyInstalled []string
+}
+
+// KubernetesApplicationVersion compares an installed application with the marketplace
+type KubernetesApplicationVersion struct {
+	Name             string
+	Plan             string
+	InstalledVersion string
+	LatestVersion    string
+	// UpdateAvailable is only true when both versions can be compared
+	UpdateAvailable bool
+	// InMarketplace is false for applications which are no longer listed
+	InMarketplace bool
+}
+
+// Applications returns the plan as KubernetesClusterConfig.Applications, such as
+// "cert-manager,longhorn:5GB,-traefik2-nodeport"
+func (p *KubernetesApplicationPlan) Applications() string {
+	entries := []string{}
+	for _, install := range p.Install {
+		entry := install.Name
+		if install.Plan != "" {
+			entry += ":" + install.Plan
+		}
+		entries = append(entries, entry)
+	}
+	for _, name := range p.Uninstall {
+		entries = append(entries, "-"+name)
+	}
+	return strings.Join(entries, ",")
+}
+
+// Empty returns true if the plan makes no changes
+func (p *KubernetesApplicationPlan) Empty() bool {
+	return len(p.Install) == 0 && len(p.Uninstall) == 0
+}
+
+// Apply sends the plan to UpdateKubernetesCluster, doing nothing if it's empty
+func (p *KubernetesApplicationPlan) Apply(client Clienter) (*KubernetesCluster, error) {
+	if p.Empty() {
+		return client.GetKubernetesCluster(p.ClusterID)
+	}
+	return client.UpdateKubernetesCluster(p.ClusterID, &KubernetesClusterConfig{Applications: p.Applications()})
+}
+
+// InstallKubernetesApplications installs applications and their dependencies on the cluster
+func (c *Client) InstallKubernetesApplications(clusterID string, applications ...KubernetesApplicationInstall) (*KubernetesCluster, error) {
+	plan, err := PlanKubernetesApplications(c, clusterID, applications, nil)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Apply(c)
+}
+
+// UninstallKubernetesApplications removes applications from the cluster
+func (c *Client) UninstallKubernetesApplications(clusterID string, names ...string) (*KubernetesCluster, error) {
+	plan, err := PlanKubernetesApplications(c, clusterID, nil, names)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Apply(c)
+}
+
+// PlanKubernetesApplications checks the applications against the marketplace and
+// the cluster's installed applications. Dependencies which aren't installed are
+// added ahead of the applications needing them, and an application can't be
+// uninstalled while one which stays installed depends on it.
+func PlanKubernetesApplications(client Clienter, clusterID string, install []KubernetesApplicationInstall, uninstall []string) (*KubernetesApplicationPlan, error) {
+	cluster, err := client.GetKubernetesCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	marketplace, err := client.ListKubernetesMarketplaceApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	planner := &kubernetesApplicationPlanner{
+		marketplace: marketplace,
+		installed:   map[string]bool{},
+		planned:     map[string]bool{},
+		plan:        &KubernetesApplicationPlan{ClusterID: clusterID, Install: []KubernetesApplicationInstall{}, Uninstall: []string{}, AlreadyInstalled: []string{}},
+	}
+	for _, application := range cluster.InstalledApplications {
+		planner.installed[strings.ToLower(installedApplicationName(application))] = true
+	}
+
+	removing := map[string]bool{}
+	for _, name := range uninstall {
+		if !planner.installed[strings.ToLower(name)] {
+			err := fmt.Errorf("application %s isn't installed on cluster %s", name, cluster.Name)
+			return nil, DatabaseKubernetesApplicationNotFoundError.wrap(err)
+		}
+		removing[strings.ToLower(name)] = true
+		planner.plan.Uninstall = append(planner.plan.Uninstall, name)
+	}
+
+	for _, application := range install {
+		if removing[strings.ToLower(application.Name)] {
+			err := fmt.Errorf("application %s is being both installed and uninstalled", application.Name)
+			return nil, KubernetesApplicationInvalidError.wrap(err)
+		}
+		if err := planner.add(application, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, application := range cluster.InstalledApplications {
+		if removing[strings.ToLower(installedApplicationName(application))] {
+			continue
+		}
+		for _, dependency := range planner.dependencies(application) {
+			if removing[strings.ToLower(dependency)] {
+				err := fmt.Errorf("unable to uninstall %s, %s depends on it", dependency, installedApplicationName(application))
+				return nil, KubernetesApplicationInvalidError.wrap(err)
+			}
+		}
+	}
+	for _, application := range planner.plan.Install {
+		for _, dependency := range planner.find(application.Name).Dependencies {
+			if removing[strings.ToLower(dependency)] {
+				err := fmt.Errorf("unable to uninstall %s, %s depends on it", dependency, application.Name)
+				return nil, KubernetesApplicationInvalidError.wrap(err)
+			}
+		}
+	}
+
+	return planner.plan, nil
+}
+
+// KubernetesApplicationVersions compares the cluster's installed applications with the marketplace
+func (c *Client) KubernetesApplicationVersions(clusterID string) ([]KubernetesApplicationVersion, error) {
+	cluster, err := c.GetKubernetesCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	marketplace, err := c.ListKubernetesMarketplaceApplications()
+	if err != nil {
+		return nil, err
+	}
+	return CompareKubernetesApplicationVersions(cluster.InstalledApplications, marketplace), nil
+}
+
+// CompareKubernetesApplicationVersions reports each installed application's
+// version against the marketplace's latest, sorted by name
+func CompareKubernetesApplicationVersions(installed []KubernetesInstalledApplication, marketplace []KubernetesMarketplaceApplication) []KubernetesApplicationVersion {
+	versions := []KubernetesApplicationVersion{}
+	for _, application := range installed {
+		version := KubernetesApplicationVersion{
+			Name:             installedApplicationName(application),
+			Plan:             application.Plan,
+			InstalledVersion: application.Version,
+		}
+		for _, app := range marketplace {
+			if strings.EqualFold(app.Name, version.Name) {
+				version.InMarketplace = true
+				version.LatestVersion = app.Version
+			}
+		}
+
+		installedVersion, installedOK := parseKubernetesVersion(version.InstalledVersion)
+		latestVersion, latestOK := parseKubernetesVersion(version.LatestVersion)
+		version.UpdateAvailable = installedOK && latestOK && compareKubernetesVersions(latestVersion, installedVersion) > 0
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Name < versions[j].Name })
+	return versions
+}
+
+type kubernetesApplicationPlanner struct {
+	marketplace []KubernetesMarketplaceApplication
+	installed   map[string]bool
+	planned     map[string]bool
+	plan        *KubernetesApplicationPlan
+}
+
+func (p *kubernetesApplicationPlanner) find(name string) *KubernetesMarketplaceApplication {
+	for i := range p.marketplace {
+		if strings.EqualFold(p.marketplace[i].Name, name) {
+			return &p.marketplace[i]
+		}
+	}
+	return nil
+}
+
+// add plans application after its dependencies, path is the chain of
+// applications which led to it, used to spot dependency cycles
+func (p *kubernetesApplicationPlanner) add(application KubernetesApplicationInstall, path []string) error {
+	key := strings.ToLower(application.Name)
+	for _, previous := range path {
+		if strings.EqualFold(previous, application.Name) {
+			err := fmt.Errorf("applications have a dependency cycle: %s -> %s", strings.Join(path, " -> "), application.Name)
+			return KubernetesApplicationInvalidError.wrap(err)
+		}
+	}
+
+	app := p.find(application.Name)
+	if app == nil {
+		err := fmt.Errorf("application %s isn't in the marketplace", application.Name)
+		return DatabaseKubernetesApplicationNotFoundError.wrap(err)
+	}
+	if p.planned[key] {
+		return nil
+	}
+	if p.installed[key] && application.Plan == "" && len(application.Configuration) == 0 {
+		p.planned[key] = true
+		p.plan.AlreadyInstalled = append(p.plan.AlreadyInstalled, app.Name)
+		return nil
+	}
+
+	plan, err := chooseKubernetesApplicationPlan(app, application)
+	if err != nil {
+		return err
+	}
+	for _, dependency := range app.Dependencies {
+		if err := p.add(KubernetesApplicationInstall{Name: dependency}, append(path, app.Name)); err != nil {
+			return err
+		}
+	}
+
+	p.planned[key] = true
+	p.plan.Install = append(p.plan.Install, KubernetesApplicationInstall{Name: app.Name, Plan: plan, Configuration: application.Configuration})
+	return nil
+}
+
+// dependencies returns the marketplace's dependencies for an installed
+// application, falling back to the ones it was installed with
+func (p *kubernetesApplicationPlanner) dependencies(application KubernetesInstalledApplication) []string {
+	if app := p.find(installedApplicationName(application)); app != nil {
+		return app.Dependencies
+	}
+	return application.Dependencies
+}
+
+// chooseKubernetesApplicationPlan returns the label of the plan to install. An
+// application with plans needs one chosen by label, by its configuration, or by
+// being the only one.
+func chooseKubernetesApplicationPlan(app *KubernetesMarketplaceApplication, application KubernetesApplicationInstall) (string, error) {
+	if len(app.Plans) == 0 {
+		if application.Plan != "" || len(application.Configuration) > 0 {
+			err := fmt.Errorf("application %s doesn't have plans", app.Name)
+			return "", DatabaseKubernetesApplicationInvalidPlanError.wrap(err)
+		}
+		return "", nil
+	}
+
+	candidates := []KubernetesMarketplacePlan{}
+	labels := []string{}
+	for _, plan := range app.Plans {
+		labels = append(labels, plan.Label)
+		if application.Plan == "" || strings.EqualFold(plan.Label, application.Plan) {
+			candidates = append(candidates, plan)
+		}
+	}
+	if len(candidates) == 0 {
+		err := fmt.Errorf("application %s doesn't have a plan %s, choose from %s", app.Name, application.Plan, strings.Join(labels, ", "))
+		return "", DatabaseKubernetesApplicationInvalidPlanError.wrap(err)
+	}
+
+	keys := []string{}
+	for key := range application.Configuration {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	matching := []KubernetesMarketplacePlan{}
+	problems := []string{}
+	for _, plan := range candidates {
+		mismatches := []string{}
+		for _, key := range keys {
+			value, ok := plan.Configuration[key]
+			switch {
+			case !ok:
+				mismatches = append(mismatches, fmt.Sprintf("plan %s has no configuration key %s", plan.Label, key))
+			case value.Value != application.Configuration[key]:
+				mismatches = append(mismatches, fmt.Sprintf("plan %s sets %s to %s", plan.Label, key, value.Value))
+			}
+		}
+		if len(mismatches) == 0 {
+			matching = append(matching, plan)
+		}
+		problems = append(problems, mismatches...)
+	}
+
+	switch {
+	case len(matching) == 1:
+		return matching[0].Label, nil
+	case len(matching) == 0:
+		err := fmt.Errorf("no plan for application %s matches the configuration: %s", app.Name, strings.Join(problems, ", "))
+		return "", DatabaseKubernetesApplicationInvalidPlanError.wrap(err)
+	default:
+		err := fmt.Errorf("application %s needs a plan, choose from %s", app.Name, strings.Join(labels, ", "))
+		return "", DatabaseKubernetesApplicationInvalidPlanError.wrap(err)
+	}
+}
+
+// installedApplicationName returns the application's marketplace name, which
+// older clusters only report in Application
+func installedApplicationName(application KubernetesInstalledApplication) string {
+	if application.Name != "" {
+		return application.Name
+	}
+	return application.Application
+}
@@ -0,0 +1,92 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestInstallKubernetesApplications(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	client.MarketplaceApplications = []KubernetesMarketplaceApplication{
+		{Name: "cert-manager", Version: "v1.14.4"},
+		{Name: "Traefik2-nodeport", Version: "2.10.7"},
+		{Name: "rancher", Version: "2.8.2", Dependencies: []string{"cert-manager"}},
+		{Name: "longhorn", Version: "1.6.0", Plans: []KubernetesMarketplacePlan{
+			{Label: "5GB", Configuration: map[string]KubernetesPlanConfiguration{"VOLUME_SIZE": {Value: "5Gi"}}},
+			{Label: "10GB", Configuration: map[string]KubernetesPlanConfiguration{"VOLUME_SIZE": {Value: "10Gi"}}},
+		}},
+		{Name: "loop-a", Version: "1.0.0", Dependencies: []string{"loop-b"}},
+		{Name: "loop-b", Version: "1.0.0", Dependencies: []string{"loop-a"}},
+	}
+	cluster, _ := client.NewKubernetesClusters(&KubernetesClusterConfig{Name: "production", Applications: "Traefik2-nodeport"})
+	client.Clusters[0].InstalledApplications[0].Version = "2.9.0"
+	clusterID := cluster.ID
+
+	// Invalid plans are rejected before anything is installed
+	invalid := map[string]struct {
+		install   KubernetesApplicationInstall
+		uninstall []string
+		kind      error
+		message   string
+	}{
+		"missing": {KubernetesApplicationInstall{Name: "istio"}, nil, DatabaseKubernetesApplicationNotFoundError,
+			"application istio isn't in the marketplace"},
+		"no plan": {KubernetesApplicationInstall{Name: "longhorn"}, nil, DatabaseKubernetesApplicationInvalidPlanError,
+			"application longhorn needs a plan, choose from 5GB, 10GB"},
+		"bad plan": {KubernetesApplicationInstall{Name: "longhorn", Plan: "1TB"}, nil, DatabaseKubernetesApplicationInvalidPlanError,
+			"application longhorn doesn't have a plan 1TB, choose from 5GB, 10GB"},
+		"bad configuration": {KubernetesApplicationInstall{Name: "longhorn", Plan: "5GB", Configuration: map[string]string{"VOLUME_SIZE": "10Gi", "REPLICAS": "2"}}, nil,
+			DatabaseKubernetesApplicationInvalidPlanError,
+			"no plan for application longhorn matches the configuration: plan 5GB has no configuration key REPLICAS, plan 5GB sets VOLUME_SIZE to 5Gi"},
+		"no plans": {KubernetesApplicationInstall{Name: "cert-manager", Plan: "small"}, nil, DatabaseKubernetesApplicationInvalidPlanError,
+			"application cert-manager doesn't have plans"},
+		"cycle": {KubernetesApplicationInstall{Name: "loop-a"}, nil, KubernetesApplicationInvalidError,
+			"applications have a dependency cycle: loop-a -> loop-b -> loop-a"},
+		"not installed": {KubernetesApplicationInstall{}, []string{"rancher"}, DatabaseKubernetesApplicationNotFoundError,
+			"application rancher isn't installed on cluster production"},
+	}
+	for name, test := range invalid {
+		install := []KubernetesApplicationInstall{}
+		if test.install.Name != "" {
+			install = append(install, test.install)
+		}
+		_, err := PlanKubernetesApplications(client, clusterID, install, test.uninstall)
+		g.Expect(errors.Is(err, test.kind)).To(BeTrue(), name)
+		g.Expect(err.Error()).To(HaveSuffix(test.message), name)
+	}
+
+	plan, err := PlanKubernetesApplications(client, clusterID, []KubernetesApplicationInstall{
+		{Name: "Rancher"},
+		{Name: "longhorn", Configuration: map[string]string{"VOLUME_SIZE": "10Gi"}},
+		{Name: "traefik2-nodeport"},
+	}, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(plan.Applications()).To(Equal("cert-manager,rancher,longhorn:10GB"))
+	g.Expect(plan.AlreadyInstalled).To(Equal([]string{"Traefik2-nodeport"}))
+
+	cluster, err = plan.Apply(client)
+	g.Expect(err).To(BeNil())
+	g.Expect(cluster.InstalledApplications).To(HaveLen(4))
+	g.Expect(cluster.InstalledApplications[3].Configuration).To(Equal(map[string]ApplicationConfiguration{"VOLUME_SIZE": {"value": "10Gi"}}))
+
+	versions := CompareKubernetesApplicationVersions(cluster.InstalledApplications, client.MarketplaceApplications)
+	g.Expect(versions[0]).To(Equal(KubernetesApplicationVersion{
+		Name: "Traefik2-nodeport", InstalledVersion: "2.9.0", LatestVersion: "2.10.7", UpdateAvailable: true, InMarketplace: true,
+	}))
+	g.Expect(versions[1].Name).To(Equal("cert-manager"))
+	g.Expect(versions[1].UpdateAvailable).To(BeFalse())
+
+	// cert-manager can't go while rancher needs it
+	_, err = PlanKubernetesApplications(client, clusterID, nil, []string{"cert-manager"})
+	g.Expect(err).To(MatchError("KubernetesApplicationInvalidError: unable to uninstall cert-manager, rancher depends on it"))
+
+	plan, err = PlanKubernetesApplications(client, clusterID, nil, []string{"rancher", "cert-manager"})
+	g.Expect(err).To(BeNil())
+	g.Expect(plan.Applications()).To(Equal("-rancher,-cert-manager"))
+	cluster, _ = plan.Apply(client)
+	g.Expect(cluster.InstalledApplications).To(HaveLen(2))
+}
@@ -0,0 +1,280 @@
+package civogo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// KubernetesPoolAction is the kind of change a KubernetesPoolStep makes
+type KubernetesPoolAction string
+
+// Actions in a KubernetesPoolPlan, steps are applied in this order
+const (
+	KubernetesPoolCreate    KubernetesPoolAction = "create"
+	KubernetesPoolScaleUp   KubernetesPoolAction = "scale-up"
+	KubernetesPoolUpdate    KubernetesPoolAction = "update"
+	KubernetesPoolScaleDown KubernetesPoolAction = "scale-down"
+	KubernetesPoolDelete    KubernetesPoolAction = "delete"
+)
+
+// KubernetesPoolStep is one change to a cluster's pools
+type KubernetesPoolStep struct {
+	Action KubernetesPoolAction
+	PoolID string
+	// Config is the pool to create, set for create steps
+	Config *KubernetesClusterPoolConfig
+	// Update is sent to UpdateKubernetesClusterPool, set for scale and update steps
+	Update *KubernetesClusterPoolUpdateConfig
+	// CurrentCount and Count are the pool's node count before and after the step
+	CurrentCount int
+	Count        int
+	// Changes lists what an update step changes, "labels" and/or "taints"
+	Changes []string
+}
+
+// String describes the step, such as "scale-up pool workers from 1 to 3 nodes"
+func (s KubernetesPoolStep) String() string {
+	switch s.Action {
+	case KubernetesPoolCreate:
+		return fmt.Sprintf("create pool %s with %d %s nodes", s.PoolID, s.Count, s.Config.Size)
+	case KubernetesPoolScaleUp, KubernetesPoolScaleDown:
+		return fmt.Sprintf("%s pool %s from %d to %d nodes", s.Action, s.PoolID, s.CurrentCount, s.Count)
+	case KubernetesPoolUpdate:
+		return fmt.Sprintf("update pool %s %s", s.PoolID, strings.Join(s.Changes, " and "))
+	default:
+		return fmt.Sprintf("%s pool %s", s.Action, s.PoolID)
+	}
+}
+
+// KubernetesPoolPlan is the changes needed to make a cluster's pools match a
+// desired list. Steps create pools first and delete them last, so the cluster
+// doesn't lose capacity part way through.
+type KubernetesPoolPlan struct {
+	ClusterID string
+	Steps     []KubernetesPoolStep
+}
+
+// Empty returns true if the pools already match
+func (p *KubernetesPoolPlan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// ReconcileKubernetesClusterPools makes the cluster's pools match desired, see
+// PlanKubernetesClusterPools, returning the plan it applied
+func (c *Client) ReconcileKubernetesClusterPools(clusterID string, desired []KubernetesClusterPoolConfig) (*KubernetesPoolPlan, error) {
+	return ReconcileKubernetesClusterPools(c, clusterID, desired)
+}
+
+// ReconcileKubernetesClusterPools plans and applies the changes to make the
+// cluster's pools match desired, returning the plan. On error the plan is
+// returned with the steps that were applied.
+func ReconcileKubernetesClusterPools(client Clienter, clusterID string, desired []KubernetesClusterPoolConfig) (*KubernetesPoolPlan, error) {
+	plan, err := PlanKubernetesClusterPools(client, clusterID, desired)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := plan.Apply(client)
+	if err != nil {
+		return &KubernetesPoolPlan{ClusterID: clusterID, Steps: applied}, err
+	}
+	return plan, nil
+}
+
+// PlanKubernetesClusterPools compares desired with the cluster's pools, matching
+// them by ID. Pools which aren't in desired are deleted. A pool's size and public
+// IP can't be changed, give the pool a new ID to replace it instead.
+func PlanKubernetesClusterPools(client Clienter, clusterID string, desired []KubernetesClusterPoolConfig) (*KubernetesPoolPlan, error) {
+	if err := validateKubernetesPoolConfigs(desired); err != nil {
+		return nil, err
+	}
+	current, err := client.ListKubernetesClusterPools(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return planKubernetesClusterPools(clusterID, current, desired)
+}
+
+// Apply makes the plan's changes in order, stopping at the first which fails
+// and returning the steps that were applied
+func (p *KubernetesPoolPlan) Apply(client Clienter) ([]KubernetesPoolStep, error) {
+	applied := []KubernetesPoolStep{}
+	for _, step := range p.Steps {
+		var err error
+		switch step.Action {
+		case KubernetesPoolCreate:
+			config := *step.Config
+			_, err = client.CreateKubernetesClusterPool(p.ClusterID, &config)
+		case KubernetesPoolScaleUp, KubernetesPoolUpdate, KubernetesPoolScaleDown:
+			_, err = client.UpdateKubernetesClusterPool(p.ClusterID, step.PoolID, step.Update)
+		case KubernetesPoolDelete:
+			_, err = client.DeleteKubernetesClusterPool(p.ClusterID, step.PoolID)
+		}
+		if err != nil {
+			return applied, fmt.Errorf("unable to %s: %w", step, err)
+		}
+		applied = append(applied, step)
+	}
+	return applied, nil
+}
+
+func validateKubernetesPoolConfigs(desired []KubernetesClusterPoolConfig) error {
+	problems := []string{}
+	if len(desired) == 0 {
+		problems = append(problems, "a cluster needs at least one pool")
+	}
+	seen := map[string]bool{}
+	for i, pool := range desired {
+		if pool.ID == "" {
+			problems = append(problems, fmt.Sprintf("pool %d has no ID", i+1))
+			continue
+		}
+		if seen[pool.ID] {
+			problems = append(problems, fmt.Sprintf("pool %s is listed more than once", pool.ID))
+		}
+		seen[pool.ID] = true
+		if pool.Count < 1 {
+			problems = append(problems, fmt.Sprintf("pool %s needs at least one node", pool.ID))
+		}
+	}
+	if len(problems) > 0 {
+		return KubernetesClusterPoolInvalidError.wrap(fmt.Errorf("%s", strings.Join(problems, ", ")))
+	}
+	return nil
+}
+
+func planKubernetesClusterPools(clusterID string, current []KubernetesPool, desired []KubernetesClusterPoolConfig) (*KubernetesPoolPlan, error) {
+	existing := map[string]KubernetesPool{}
+	for _, pool := range current {
+		existing[pool.ID] = pool
+	}
+
+	steps := []KubernetesPoolStep{}
+	problems := []string{}
+	wanted := map[string]bool{}
+	for _, config := range desired {
+		wanted[config.ID] = true
+		pool, ok := existing[config.ID]
+		if !ok {
+			if config.Size == "" {
+				problems = append(problems, fmt.Sprintf("new pool %s has no size", config.ID))
+				continue
+			}
+			config := config
+			config.Taints = append([]corev1.Taint{}, config.Taints...)
+			steps = append(steps, KubernetesPoolStep{Action: KubernetesPoolCreate, PoolID: config.ID, Config: &config, Count: config.Count})
+			continue
+		}
+
+		if config.Size != "" && config.Size != pool.Size {
+			problems = append(problems, fmt.Sprintf("pool %s's size can't be changed from %s to %s, give it a new ID to replace it", pool.ID, pool.Size, config.Size))
+		}
+		if config.PublicIPNodePool != pool.PublicIPNodePool {
+			problems = append(problems, fmt.Sprintf("pool %s's public IP setting can't be changed, give it a new ID to replace it", pool.ID))
+		}
+
+		// Updates always send the labels and taints, as leaving taints out clears
+		// them. Scaling up happens before the labels and taints are updated and
+		// scaling down afterwards, so each sends what the pool has at that point.
+		currentTaints := pool.Taints
+		if currentTaints == nil {
+			currentTaints = []corev1.Taint{}
+		}
+		labels, taints := pool.Labels, pool.Taints
+		changes := []string{}
+		if !sameKubernetesLabels(pool.Labels, config.Labels) {
+			labels = config.Labels
+			changes = append(changes, "labels")
+		}
+		if !sameKubernetesTaints(pool.Taints, config.Taints) {
+			taints = config.Taints
+			changes = append(changes, "taints")
+		}
+		if taints == nil {
+			taints = []corev1.Taint{}
+		}
+		if len(changes) > 0 {
+			steps = append(steps, KubernetesPoolStep{
+				Action:       KubernetesPoolUpdate,
+				PoolID:       pool.ID,
+				Update:       &KubernetesClusterPoolUpdateConfig{Labels: labels, Taints: taints},
+				CurrentCount: pool.Count,
+				Count:        pool.Count,
+				Changes:      changes,
+			})
+		}
+
+		if config.Count != pool.Count {
+			count := config.Count
+			update := &KubernetesClusterPoolUpdateConfig{Count: &count, Labels: pool.Labels, Taints: currentTaints}
+			action := KubernetesPoolScaleUp
+			if count < pool.Count {
+				action = KubernetesPoolScaleDown
+				update.Labels, update.Taints = labels, taints
+			}
+			steps = append(steps, KubernetesPoolStep{
+				Action:       action,
+				PoolID:       pool.ID,
+				Update:       update,
+				CurrentCount: pool.Count,
+				Count:        count,
+			})
+		}
+	}
+
+	for _, pool := range current {
+		if !wanted[pool.ID] {
+			steps = append(steps, KubernetesPoolStep{Action: KubernetesPoolDelete, PoolID: pool.ID, CurrentCount: pool.Count})
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, KubernetesClusterPoolInvalidError.wrap(fmt.Errorf("%s", strings.Join(problems, ", ")))
+	}
+
+	order := map[KubernetesPoolAction]int{
+		KubernetesPoolCreate:    0,
+		KubernetesPoolScaleUp:   1,
+		KubernetesPoolUpdate:    2,
+		KubernetesPoolScaleDown: 3,
+		KubernetesPoolDelete:    4,
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return order[steps[i].Action] < order[steps[j].Action] })
+	return &KubernetesPoolPlan{ClusterID: clusterID, Steps: steps}, nil
+}
+
+func sameKubernetesLabels(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// sameKubernetesTaints compares taints by key, value and effect, ignoring their order
+func sameKubernetesTaints(a, b []corev1.Taint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	describe := func(taints []corev1.Taint) []string {
+		described := []string{}
+		for _, taint := range taints {
+			described = append(described, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+		}
+		sort.Strings(described)
+		return described
+	}
+	x, y := describe(a), describe(b)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
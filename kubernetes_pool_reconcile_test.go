@@ -0,0 +1,105 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// recordingPoolClient records each pool update before making it
+type recordingPoolClient struct {
+	*FakeClient
+	updates []KubernetesPoolStep
+}
+
+func (c *recordingPoolClient) UpdateKubernetesClusterPool(cid, pid string, config *KubernetesClusterPoolUpdateConfig) (*KubernetesPool, error) {
+	c.updates = append(c.updates, KubernetesPoolStep{PoolID: pid, Update: config})
+	return c.FakeClient.UpdateKubernetesClusterPool(cid, pid, config)
+}
+
+func TestReconcileKubernetesClusterPools(t *testing.T) {
+	g := NewWithT(t)
+
+	client, _ := NewFakeClient()
+	cluster, _ := client.NewKubernetesClusters(&KubernetesClusterConfig{Name: "production", Pools: []KubernetesClusterPoolConfig{
+		{ID: "workers", Count: 3, Size: "g4s.kube.medium", Labels: map[string]string{"tier": "web"}},
+		{ID: "batch", Count: 2, Size: "g4s.kube.large"},
+		{ID: "legacy", Count: 1, Size: "g4s.kube.small"},
+	}})
+	clusterID := cluster.ID
+
+	// Invalid plans are rejected without changing anything
+	_, err := PlanKubernetesClusterPools(client, clusterID, nil)
+	g.Expect(err).To(MatchError("KubernetesClusterPoolInvalidError: a cluster needs at least one pool"))
+
+	_, err = PlanKubernetesClusterPools(client, clusterID, []KubernetesClusterPoolConfig{
+		{Count: 1}, {ID: "batch", Count: 0}, {ID: "batch", Count: 1},
+	})
+	g.Expect(err).To(MatchError("KubernetesClusterPoolInvalidError: pool 1 has no ID, pool batch needs at least one node, pool batch is listed more than once"))
+
+	_, err = PlanKubernetesClusterPools(client, clusterID, []KubernetesClusterPoolConfig{
+		{ID: "workers", Count: 3, Size: "g4s.kube.large", PublicIPNodePool: true},
+		{ID: "gpu", Count: 1},
+	})
+	g.Expect(errors.Is(err, KubernetesClusterPoolInvalidError)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("pool workers's size can't be changed from g4s.kube.medium to g4s.kube.large"))
+	g.Expect(err.Error()).To(ContainSubstring("pool workers's public IP setting can't be changed"))
+	g.Expect(err.Error()).To(ContainSubstring("new pool gpu has no size"))
+	pools, _ := client.ListKubernetesClusterPools(clusterID)
+	g.Expect(pools).To(HaveLen(3))
+
+	taint := corev1.Taint{Key: "dedicated", Value: "batch", Effect: corev1.TaintEffectNoSchedule}
+	desired := []KubernetesClusterPoolConfig{
+		{ID: "workers", Count: 2, Labels: map[string]string{"tier": "frontend"}},
+		{ID: "batch", Count: 4, Size: "g4s.kube.large", Taints: []corev1.Taint{taint}},
+		{ID: "gpu", Count: 1, Size: "an.g1.l40s.kube.x1"},
+	}
+
+	plan, err := PlanKubernetesClusterPools(client, clusterID, desired)
+	g.Expect(err).To(BeNil())
+	descriptions := []string{}
+	for _, step := range plan.Steps {
+		descriptions = append(descriptions, step.String())
+	}
+	g.Expect(descriptions).To(Equal([]string{
+		"create pool gpu with 1 an.g1.l40s.kube.x1 nodes",
+		"scale-up pool batch from 2 to 4 nodes",
+		"update pool workers labels",
+		"update pool batch taints",
+		"scale-down pool workers from 3 to 2 nodes",
+		"delete pool legacy",
+	}))
+
+	recorder := &recordingPoolClient{FakeClient: client}
+	applied, err := ReconcileKubernetesClusterPools(recorder, clusterID, desired)
+	g.Expect(err).To(BeNil())
+	g.Expect(applied.Steps).To(HaveLen(6))
+
+	// Each update only makes its own change, keeping the labels and taints the
+	// pool has at that point
+	four, two := 4, 2
+	g.Expect(recorder.updates).To(Equal([]KubernetesPoolStep{
+		{PoolID: "batch", Update: &KubernetesClusterPoolUpdateConfig{Count: &four, Taints: []corev1.Taint{}}},
+		{PoolID: "workers", Update: &KubernetesClusterPoolUpdateConfig{Labels: map[string]string{"tier": "frontend"}, Taints: []corev1.Taint{}}},
+		{PoolID: "batch", Update: &KubernetesClusterPoolUpdateConfig{Taints: []corev1.Taint{taint}}},
+		{PoolID: "workers", Update: &KubernetesClusterPoolUpdateConfig{Count: &two, Labels: map[string]string{"tier": "frontend"}, Taints: []corev1.Taint{}}},
+	}))
+
+	pools, _ = client.ListKubernetesClusterPools(clusterID)
+	g.Expect(pools).To(HaveLen(3))
+	counts := map[string]int{}
+	for _, pool := range pools {
+		counts[pool.ID] = len(pool.Instances)
+	}
+	g.Expect(counts).To(Equal(map[string]int{"workers": 2, "batch": 4, "gpu": 1}))
+	workers, _ := client.GetKubernetesClusterPool(clusterID, "workers")
+	g.Expect(workers.Labels).To(Equal(map[string]string{"tier": "frontend"}))
+	batch, _ := client.GetKubernetesClusterPool(clusterID, "batch")
+	g.Expect(batch.Taints).To(Equal([]corev1.Taint{taint}))
+
+	again, err := PlanKubernetesClusterPools(client, clusterID, desired)
+	g.Expect(err).To(BeNil())
+	g.Expect(again.Empty()).To(BeTrue())
+}
@@ -0,0 +1,167 @@
+package civogo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// KubernetesRecycleProgress is reported each time KubernetesClusterRecycle checks the cluster
+type KubernetesRecycleProgress struct {
+	ClusterID string
+	PoolID    string
+	// Recycling holds the hostnames of the nodes in the current batch
+	Recycling []string
+	// Recycled is how many nodes have been replaced and are ready, out of Total
+	Recycled int
+	Total    int
+	Nodes    []KubernetesUpgradeNode
+	// ReadyNodes is how many of Nodes are ACTIVE
+	ReadyNodes int
+}
+
+// KubernetesClusterRecycle recycles a cluster's nodes a few at a time, waiting
+// for the replacements to be ready before moving on
+type KubernetesClusterRecycle struct {
+	Client Clienter
+	// MaxUnavailable is how many nodes are recycled at once, defaulting to 1
+	MaxUnavailable int
+	// PollInterval is how often the cluster is checked while waiting, defaulting to 15 seconds
+	PollInterval time.Duration
+	// Timeout is how long to wait for each batch of nodes, defaulting to 15 minutes
+	Timeout time.Duration
+	// Progress, when set, is called each time the cluster is checked
+	Progress func(KubernetesRecycleProgress)
+	// Sleep and Now are used while waiting, when nil they're time.Sleep and time.Now
+	Sleep func(time.Duration)
+	Now   func() time.Time
+}
+
+// NewKubernetesClusterRecycle returns a KubernetesClusterRecycle using client with the default settings
+func NewKubernetesClusterRecycle(client Clienter) *KubernetesClusterRecycle {
+	return &KubernetesClusterRecycle{Client: client, MaxUnavailable: 1}
+}
+
+// RollingRecycleKubernetesCluster recycles every node in the pool, or in the
+// whole cluster if poolID is empty, one at a time. See KubernetesClusterRecycle.Recycle.
+func (c *Client) RollingRecycleKubernetesCluster(id, poolID string) ([]string, error) {
+	return NewKubernetesClusterRecycle(c).Recycle(id, poolID)
+}
+
+// Recycle recycles the nodes in the pool, or in the whole cluster if poolID is
+// empty, MaxUnavailable at a time. After each batch it waits for the old nodes
+// to be replaced, every node to be ACTIVE and the cluster's conditions to be
+// true. It stops at the first failure, returning the hostnames of every node
+// it's asked to recycle, including any in the batch which hadn't finished.
+func (r *KubernetesClusterRecycle) Recycle(id, poolID string) ([]string, error) {
+	cluster, err := r.Client.GetKubernetesCluster(id)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := append([]KubernetesInstance{}, cluster.Instances...)
+	if poolID != "" {
+		pool, err := r.Client.GetKubernetesClusterPool(id, poolID)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append([]KubernetesInstance{}, pool.Instances...)
+	}
+	batchSize := r.MaxUnavailable
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	instances, err := r.Client.ListKubernetesClusterInstances(id)
+	if err != nil {
+		return nil, err
+	}
+	expected := len(instances)
+
+	recycled := []string{}
+	for start := 0; start < len(nodes); start += batchSize {
+		batch := nodes[start:min(start+batchSize, len(nodes))]
+		hostnames := []string{}
+		for _, node := range batch {
+			if _, err := r.Client.RecycleKubernetesCluster(id, node.Hostname); err != nil {
+				return recycled, fmt.Errorf("unable to recycle node %s: %w", node.Hostname, err)
+			}
+			hostnames = append(hostnames, node.Hostname)
+			recycled = append(recycled, node.Hostname)
+		}
+
+		progress := KubernetesRecycleProgress{ClusterID: id, PoolID: poolID, Recycling: hostnames, Recycled: start, Total: len(nodes)}
+		if err := r.wait(batch, expected, progress); err != nil {
+			return recycled, err
+		}
+	}
+
+	if r.Progress != nil {
+		r.Progress(KubernetesRecycleProgress{ClusterID: id, PoolID: poolID, Recycling: []string{}, Recycled: len(recycled), Total: len(nodes)})
+	}
+	return recycled, nil
+}
+
+// wait waits for the batch's nodes to be replaced, with expected nodes all
+// ACTIVE and the cluster's conditions settled
+func (r *KubernetesClusterRecycle) wait(batch []KubernetesInstance, expected int, progress KubernetesRecycleProgress) error {
+	old := map[string]bool{}
+	for _, node := range batch {
+		old[node.ID] = true
+	}
+
+	name := progress.ClusterID
+	return r.poller().poll(func() (bool, error) {
+		cluster, err := r.Client.GetKubernetesCluster(progress.ClusterID)
+		if err != nil {
+			return false, err
+		}
+		name = cluster.Name
+		instances, err := r.Client.ListKubernetesClusterInstances(progress.ClusterID)
+		if err != nil {
+			return false, err
+		}
+
+		progress.Nodes = []KubernetesUpgradeNode{}
+		progress.ReadyNodes = 0
+		replaced := true
+		for _, instance := range instances {
+			progress.Nodes = append(progress.Nodes, KubernetesUpgradeNode{ID: instance.ID, Hostname: instance.Hostname, Status: instance.Status})
+			switch {
+			case old[instance.ID]:
+				replaced = false
+			case instance.Status == InstanceStatusActive:
+				progress.ReadyNodes++
+			case instance.Status == InstanceStatusError:
+				err := fmt.Errorf("node %s went into %s while recycling %s", instance.Hostname, instance.Status, strings.Join(progress.Recycling, ", "))
+				return false, KubernetesClusterFailedError.wrap(err)
+			}
+		}
+		if r.Progress != nil {
+			r.Progress(progress)
+		}
+
+		if replaced && progress.ReadyNodes >= expected && kubernetesConditionsSettled(cluster) {
+			return true, nil
+		}
+		if cluster.Status == "ERROR" || cluster.Status == "FAILED" {
+			err := fmt.Errorf("cluster %s went into %s while recycling %s", cluster.Name, cluster.Status, strings.Join(progress.Recycling, ", "))
+			return false, KubernetesClusterFailedError.wrap(err)
+		}
+		return false, nil
+	}, func() error {
+		return fmt.Errorf("timed out waiting for cluster %s to recycle %s, %d of %d nodes are ready",
+			name, strings.Join(progress.Recycling, ", "), progress.ReadyNodes, expected)
+	})
+}
+
+func (r *KubernetesClusterRecycle) poller() poller {
+	return poller{
+		interval:        r.PollInterval,
+		timeout:         r.Timeout,
+		defaultInterval: 15 * time.Second,
+		defaultTimeout:  15 * time.Minute,
+		sleep:           r.Sleep,
+		now:             r.Now,
+	}
+}
@@ -0,0 +1,67 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRollingRecycleKubernetesCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	client, cluster := newFakeKubernetesCluster(&KubernetesClusterConfig{Name: "production", NumTargetNodes: 3})
+	client.ClusterBuildDuration = 4 * time.Minute
+	recycle := &KubernetesClusterRecycle{Client: client, MaxUnavailable: 2, PollInterval: time.Minute, Sleep: client.Clock.Sleep, Now: client.Clock.Now}
+
+	original := []string{}
+	for _, node := range cluster.Instances {
+		original = append(original, node.ID)
+	}
+	progress := []KubernetesRecycleProgress{}
+	recycle.Progress = func(p KubernetesRecycleProgress) { progress = append(progress, p) }
+
+	recycled, err := recycle.Recycle(cluster.ID, cluster.Pools[0].ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(recycled).To(Equal([]string{"production_pool_0", "production_pool_1", "production_pool_2"}))
+
+	// Each batch takes 4 minutes, checked every minute
+	g.Expect(progress).To(HaveLen(11))
+	g.Expect(progress[0].Recycling).To(Equal([]string{"production_pool_0", "production_pool_1"}))
+	g.Expect(progress[0].ReadyNodes).To(Equal(1))
+	g.Expect(progress[2].Nodes[0].Status).To(Equal(InstanceStatusBuilding))
+	g.Expect(progress[5].Recycling).To(Equal([]string{"production_pool_2"}))
+	g.Expect(progress[5].Recycled).To(Equal(2))
+	g.Expect(progress[10]).To(Equal(KubernetesRecycleProgress{ClusterID: cluster.ID, PoolID: cluster.Pools[0].ID, Recycling: []string{}, Recycled: 3, Total: 3}))
+
+	// Every node has been replaced
+	instances, _ := client.ListKubernetesClusterInstances(cluster.ID)
+	g.Expect(instances).To(HaveLen(3))
+	for i, instance := range instances {
+		g.Expect(instance.ID).NotTo(Equal(original[i]))
+		g.Expect(instance.Status).To(Equal(InstanceStatusActive))
+	}
+}
+
+func TestRollingRecycleKubernetesClusterStopsOnFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	client, cluster := newFakeKubernetesCluster(&KubernetesClusterConfig{Name: "production", NumTargetNodes: 3})
+	client.ClusterBuildDuration = 4 * time.Minute
+	client.Clusters[0].Pools[0].Instances[1].Hostname = "renamed"
+	recycle := &KubernetesClusterRecycle{Client: client, MaxUnavailable: 2, PollInterval: time.Minute, Sleep: client.Clock.Sleep, Now: client.Clock.Now}
+
+	// The first node in the batch was recycled before the second failed
+	recycled, err := recycle.Recycle(cluster.ID, cluster.Pools[0].ID)
+	g.Expect(recycled).To(Equal([]string{"production_pool_0"}))
+	g.Expect(errors.Is(err, DatabaseKubernetesNodeNotFoundError)).To(BeTrue())
+	g.Expect(err.Error()).To(HavePrefix("unable to recycle node renamed: "))
+
+	recycle.MaxUnavailable = 1
+	recycle.Timeout = 2 * time.Minute
+	recycled, err = recycle.Recycle(cluster.ID, "")
+	g.Expect(recycled).To(Equal([]string{"production_pool_0"}))
+	g.Expect(errors.Is(err, TimeoutError)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("timed out waiting for cluster production to recycle production_pool_0, 2 of 3 nodes are ready"))
+}
@@ -175,8 +175,8 @@ func TestFindKubernetesCluster(t *testing.T) {
 	}
 
 	_, err := client.FindKubernetesCluster("cluster")
-	if err.Error() != "MultipleMatchesError: unable to find cluster because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find cluster because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find cluster because there were multiple matches: your-first-cluster-name (69a23478-a89e-41d2-97b1-6f4c341cee70), your-second-cluster-name (d1cd0b71-5da1-492e-9d0d-a46ccdaae2fa)" {
+		t.Errorf("Expected %s, got %s", "unable to find cluster because there were multiple matches: your-first-cluster-name (69a23478-a89e-41d2-97b1-6f4c341cee70), your-second-cluster-name (d1cd0b71-5da1-492e-9d0d-a46ccdaae2fa)", err.Error())
 	}
 
 	_, err = client.FindKubernetesCluster("missing")
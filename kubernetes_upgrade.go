@@ -0,0 +1,238 @@
+package civogo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KubernetesUpgradeProgress is reported each time KubernetesClusterUpgrade checks the cluster
+type KubernetesUpgradeProgress struct {
+	ClusterID  string
+	Status     string
+	Version    string
+	Target     string
+	Conditions []Condition
+	Nodes      []KubernetesUpgradeNode
+	// ReadyNodes is how many of Nodes are ACTIVE
+	ReadyNodes int
+}
+
+// KubernetesUpgradeNode is a node's status during an upgrade
+type KubernetesUpgradeNode struct {
+	ID       string
+	Hostname string
+	Status   string
+}
+
+// KubernetesClusterUpgrade upgrades clusters a minor version at a time and
+// waits for them to settle
+type KubernetesClusterUpgrade struct {
+	Client Clienter
+	// PollInterval is how often the cluster is checked while waiting, defaulting to 15 seconds
+	PollInterval time.Duration
+	// Timeout is how long to wait for the upgrade, defaulting to 30 minutes
+	Timeout time.Duration
+	// Progress, when set, is called each time the cluster is checked
+	Progress func(KubernetesUpgradeProgress)
+	// Sleep and Now are used while waiting, when nil they're time.Sleep and time.Now
+	Sleep func(time.Duration)
+	Now   func() time.Time
+}
+
+// NewKubernetesClusterUpgrade returns a KubernetesClusterUpgrade using client with the default timings
+func NewKubernetesClusterUpgrade(client Clienter) *KubernetesClusterUpgrade {
+	return &KubernetesClusterUpgrade{Client: client}
+}
+
+// UpgradeKubernetesCluster upgrades the cluster to version, or to its
+// UpgradeAvailableTo if version is empty, using the default timings. See
+// KubernetesClusterUpgrade.Upgrade.
+func (c *Client) UpgradeKubernetesCluster(id, version string) (*KubernetesCluster, error) {
+	return NewKubernetesClusterUpgrade(c).Upgrade(id, version)
+}
+
+// Upgrade checks version is an available upgrade for the cluster, requests it
+// and waits for the cluster to report the new version with all its conditions
+// true. An empty version means the cluster's UpgradeAvailableTo.
+func (u *KubernetesClusterUpgrade) Upgrade(id, version string) (*KubernetesCluster, error) {
+	cluster, err := u.Client.GetKubernetesCluster(id)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = cluster.UpgradeAvailableTo
+	}
+	if version == "" {
+		err := fmt.Errorf("cluster %s has no upgrade available", cluster.Name)
+		return nil, KubernetesUpgradeInvalidError.wrap(err)
+	}
+
+	versions, err := u.Client.ListAvailableKubernetesVersions()
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckKubernetesUpgrade(cluster, version, versions); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.Client.UpdateKubernetesCluster(id, &KubernetesClusterConfig{KubernetesVersion: version}); err != nil {
+		return nil, err
+	}
+	return u.wait(id, version)
+}
+
+// CheckKubernetesUpgrade returns KubernetesUpgradeInvalidError unless version is
+// one of versions, for the cluster's type, and the next minor version (or a
+// patch release of the current one) after the cluster's version
+func CheckKubernetesUpgrade(cluster *KubernetesCluster, version string, versions []KubernetesVersion) error {
+	current := kubernetesClusterVersion(cluster)
+
+	var target *KubernetesVersion
+	for i := range versions {
+		if versions[i].Version == version {
+			target = &versions[i]
+		}
+	}
+	if target == nil {
+		err := fmt.Errorf("version %s isn't available", version)
+		return KubernetesUpgradeInvalidError.wrap(err)
+	}
+	if target.ClusterType != "" && cluster.ClusterType != "" && target.ClusterType != cluster.ClusterType {
+		err := fmt.Errorf("version %s is for %s clusters, cluster %s is %s", version, target.ClusterType, cluster.Name, cluster.ClusterType)
+		return KubernetesUpgradeInvalidError.wrap(err)
+	}
+
+	from, ok := parseKubernetesVersion(current)
+	if !ok {
+		err := fmt.Errorf("unable to parse cluster %s's version %q", cluster.Name, current)
+		return KubernetesUpgradeInvalidError.wrap(err)
+	}
+	to, ok := parseKubernetesVersion(version)
+	if !ok {
+		err := fmt.Errorf("unable to parse version %q", version)
+		return KubernetesUpgradeInvalidError.wrap(err)
+	}
+
+	if compareKubernetesVersions(to, from) <= 0 {
+		err := fmt.Errorf("version %s isn't newer than cluster %s's version %s", version, cluster.Name, current)
+		return KubernetesUpgradeInvalidError.wrap(err)
+	}
+	if to[0] != from[0] || to[1] > from[1]+1 {
+		err := fmt.Errorf("unable to upgrade cluster %s from %s to %s, upgrade to %d.%d first", cluster.Name, current, version, from[0], from[1]+1)
+		return KubernetesUpgradeInvalidError.wrap(err)
+	}
+	return nil
+}
+
+func (u *KubernetesClusterUpgrade) wait(id, version string) (*KubernetesCluster, error) {
+	var cluster *KubernetesCluster
+	var progress KubernetesUpgradeProgress
+	err := u.poller().poll(func() (bool, error) {
+		current, err := u.Client.GetKubernetesCluster(id)
+		if err != nil {
+			return false, err
+		}
+		instances, err := u.Client.ListKubernetesClusterInstances(id)
+		if err != nil {
+			return false, err
+		}
+		cluster = current
+
+		progress = KubernetesUpgradeProgress{
+			ClusterID:  id,
+			Status:     cluster.Status,
+			Version:    kubernetesClusterVersion(cluster),
+			Target:     version,
+			Conditions: cluster.Conditions,
+			Nodes:      []KubernetesUpgradeNode{},
+		}
+		for _, instance := range instances {
+			progress.Nodes = append(progress.Nodes, KubernetesUpgradeNode{ID: instance.ID, Hostname: instance.Hostname, Status: instance.Status})
+			if instance.Status == InstanceStatusActive {
+				progress.ReadyNodes++
+			}
+		}
+		if u.Progress != nil {
+			u.Progress(progress)
+		}
+
+		if progress.Version == version && progress.ReadyNodes == len(progress.Nodes) && kubernetesConditionsSettled(cluster) {
+			return true, nil
+		}
+		if cluster.Status == "ERROR" || cluster.Status == "FAILED" {
+			err := fmt.Errorf("cluster %s went into %s while upgrading to %s", cluster.Name, cluster.Status, version)
+			return false, KubernetesClusterFailedError.wrap(err)
+		}
+		return false, nil
+	}, func() error {
+		return fmt.Errorf("timed out waiting for cluster %s to upgrade to %s, it's on %s with %d of %d nodes ready and %s not ready",
+			cluster.Name, version, progress.Version, progress.ReadyNodes, len(progress.Nodes), strings.Join(cluster.HealthSummary().NotReady, ", "))
+	})
+	return cluster, err
+}
+
+func (u *KubernetesClusterUpgrade) poller() poller {
+	return poller{
+		interval:        u.PollInterval,
+		timeout:         u.Timeout,
+		defaultInterval: 15 * time.Second,
+		defaultTimeout:  30 * time.Minute,
+		sleep:           u.Sleep,
+		now:             u.Now,
+	}
+}
+
+// kubernetesConditionsSettled returns true if the cluster is ready with every condition true and synced
+func kubernetesConditionsSettled(cluster *KubernetesCluster) bool {
+	if !cluster.Ready {
+		return false
+	}
+	for _, condition := range cluster.Conditions {
+		if condition.Status != metav1.ConditionTrue || !condition.Synced {
+			return false
+		}
+	}
+	return true
+}
+
+func kubernetesClusterVersion(cluster *KubernetesCluster) string {
+	if cluster.KubernetesVersion != "" {
+		return cluster.KubernetesVersion
+	}
+	return cluster.Version
+}
+
+var kubernetesVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseKubernetesVersion returns the major, minor and patch numbers of versions
+// such as "1.28.7-k3s1" or "1.20+k3s1"
+func parseKubernetesVersion(version string) ([3]int, bool) {
+	match := kubernetesVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return [3]int{}, false
+	}
+	parsed := [3]int{}
+	for i, part := range match[1:] {
+		if part != "" {
+			parsed[i], _ = strconv.Atoi(part)
+		}
+	}
+	return parsed, true
+}
+
+func compareKubernetesVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
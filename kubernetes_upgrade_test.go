@@ -0,0 +1,101 @@
+package civogo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testKubernetesVersions = []KubernetesVersion{
+	{Version: "1.27.1-k3s1", Type: "stable", ClusterType: "k3s"},
+	{Version: "1.28.7-k3s1", Type: "stable", ClusterType: "k3s"},
+	{Version: "1.29.2-k3s1", Type: "stable", ClusterType: "k3s"},
+	{Version: "1.28.2-talos", Type: "stable", ClusterType: "talos"},
+}
+
+func TestUpgradeKubernetesCluster(t *testing.T) {
+	g := NewWithT(t)
+
+	client, cluster := newFakeKubernetesCluster(&KubernetesClusterConfig{Name: "production", ClusterType: "k3s", KubernetesVersion: "1.27.1-k3s1", NumTargetNodes: 2})
+	client.KubernetesVersions = testKubernetesVersions
+	client.Clusters[0].UpgradeAvailableTo = "1.28.7-k3s1"
+	client.ClusterBuildDuration = 9 * time.Minute
+
+	upgrade := &KubernetesClusterUpgrade{Client: client, PollInterval: time.Minute, Sleep: client.Clock.Sleep, Now: client.Clock.Now}
+	progress := []KubernetesUpgradeProgress{}
+	upgrade.Progress = func(p KubernetesUpgradeProgress) { progress = append(progress, p) }
+
+	upgraded, err := upgrade.Upgrade(cluster.ID, "")
+	g.Expect(err).To(BeNil())
+	g.Expect(upgraded.KubernetesVersion).To(Equal("1.28.7-k3s1"))
+	g.Expect(upgraded.Ready).To(BeTrue())
+
+	// The nodes finish one at a time, every 3 minutes
+	readyNodes := []int{}
+	for _, p := range progress {
+		readyNodes = append(readyNodes, p.ReadyNodes)
+	}
+	g.Expect(readyNodes).To(Equal([]int{0, 0, 0, 1, 1, 1, 2, 2, 2, 2}))
+	g.Expect(progress[0].Status).To(Equal("ACTIVE"))
+	g.Expect(progress[0].Conditions[0].Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(progress[0].Nodes).To(HaveLen(2))
+	g.Expect(progress[0].Nodes[0].Status).To(Equal(InstanceStatusRebooting))
+	g.Expect(progress[len(progress)-1].Version).To(Equal("1.28.7-k3s1"))
+}
+
+func TestUpgradeKubernetesClusterTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	client, cluster := newFakeKubernetesCluster(&KubernetesClusterConfig{Name: "production", ClusterType: "k3s", KubernetesVersion: "1.27.1-k3s1", NumTargetNodes: 2})
+	client.KubernetesVersions = testKubernetesVersions
+	client.ClusterBuildDuration = 9 * time.Minute
+
+	upgrade := &KubernetesClusterUpgrade{Client: client, PollInterval: time.Minute, Timeout: 5 * time.Minute, Sleep: client.Clock.Sleep, Now: client.Clock.Now}
+
+	_, err := upgrade.Upgrade(cluster.ID, "1.28.7-k3s1")
+	g.Expect(errors.Is(err, TimeoutError)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("it's on 1.27.1-k3s1 with 1 of 2 nodes ready and ControlPlaneReady, WorkerNodesReady, ClusterVersionSync not ready"))
+
+	// The upgrade still finishes in the background
+	client.Clock.Advance(10 * time.Minute)
+	got, _ := client.GetKubernetesCluster(cluster.ID)
+	g.Expect(got.KubernetesVersion).To(Equal("1.28.7-k3s1"))
+
+	// Without a clock the upgrade finishes at once, NewKubernetesClusterUpgrade uses the default timings
+	client.Clock = nil
+	got, err = NewKubernetesClusterUpgrade(client).Upgrade(cluster.ID, "1.29.2-k3s1")
+	g.Expect(err).To(BeNil())
+	g.Expect(got.KubernetesVersion).To(Equal("1.29.2-k3s1"))
+}
+
+func TestCheckKubernetesUpgrade(t *testing.T) {
+	g := NewWithT(t)
+
+	versions := testKubernetesVersions
+	cluster := &KubernetesCluster{Name: "production", ClusterType: "k3s", KubernetesVersion: "1.27.1-k3s1"}
+
+	g.Expect(CheckKubernetesUpgrade(cluster, "1.28.7-k3s1", versions)).To(Succeed())
+
+	invalid := map[string]string{
+		"1.30.0-k3s1":  "version 1.30.0-k3s1 isn't available",
+		"1.28.2-talos": "version 1.28.2-talos is for talos clusters, cluster production is k3s",
+		"1.27.1-k3s1":  "version 1.27.1-k3s1 isn't newer than cluster production's version 1.27.1-k3s1",
+		"1.29.2-k3s1":  "unable to upgrade cluster production from 1.27.1-k3s1 to 1.29.2-k3s1, upgrade to 1.28 first",
+	}
+	for version, message := range invalid {
+		err := CheckKubernetesUpgrade(cluster, version, versions)
+		g.Expect(errors.Is(err, KubernetesUpgradeInvalidError)).To(BeTrue(), version)
+		g.Expect(err).To(MatchError("KubernetesUpgradeInvalidError: "+message), version)
+	}
+
+	parsed, ok := parseKubernetesVersion("1.20+k3s1")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(parsed).To(Equal([3]int{1, 20, 0}))
+	parsed, _ = parseKubernetesVersion("v1.28.7")
+	g.Expect(parsed).To(Equal([3]int{1, 28, 7}))
+	_, ok = parseKubernetesVersion("latest")
+	g.Expect(ok).To(BeFalse())
+}
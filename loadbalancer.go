@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 )
 
 // HealthCheck represents the health check configuration for an instance pool.
@@ -156,31 +155,10 @@ func (c *Client) FindLoadBalancer(search string) (*LoadBalancer, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := LoadBalancer{}
-
-	for _, value := range lbs {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(lbs, search, c.FindMode,
+		func(l LoadBalancer) string { return l.ID },
+		func(l LoadBalancer) []string { return []string{l.Name} },
+	)
 }
 
 // CreateLoadBalancer creates a new load balancer
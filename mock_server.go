@@ -0,0 +1,271 @@
+package civogo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TestingT is the subset of testing.TB used by MockServer to report failures,
+// so that this package doesn't need to import "testing"
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// MockServer is a local HTTP server that answers API requests from a list of
+// expectations, used to test code built on Client without a real Civo API
+type MockServer struct {
+	t            TestingT
+	server       *httptest.Server
+	mu           sync.Mutex
+	expectations []*MockExpectation
+	ordered      bool
+	next         int
+}
+
+// MockExpectation describes a single request the MockServer expects to receive
+// and the response it sends back when that request arrives
+type MockExpectation struct {
+	method          string
+	path            string
+	query           map[string]string
+	body            string
+	matchBody       bool
+	statusCode      int
+	responseBody    string
+	responseHeaders map[string]string
+	times           int
+	calls           int
+}
+
+// NewMockServer starts a local test server which reports unmatched requests
+// and unmet expectations to t
+func NewMockServer(t TestingT) *MockServer {
+	m := &MockServer{t: t}
+	m.server = httptest.NewServer(http.HandlerFunc(m.handle))
+	return m
+}
+
+// Client returns a Client connected to the mock server
+func (m *MockServer) Client() (*Client, error) {
+	return NewClientForTestingWithServer(m.server)
+}
+
+// Server returns the underlying httptest.Server
+func (m *MockServer) Server() *httptest.Server {
+	return m.server
+}
+
+// InOrder requires requests to arrive in the same order the expectations were added
+func (m *MockServer) InOrder() *MockServer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ordered = true
+	return m
+}
+
+// Expect adds an expectation for a request with the given method and path,
+// which by default is answered with a 200 and an empty JSON object
+func (m *MockServer) Expect(method, path string) *MockExpectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &MockExpectation{
+		method:          strings.ToUpper(method),
+		path:            path,
+		query:           map[string]string{},
+		statusCode:      http.StatusOK,
+		responseBody:    "{}",
+		responseHeaders: map[string]string{},
+	}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// Close shuts the server down and reports any expectations that weren't met
+func (m *MockServer) Close() {
+	m.server.Close()
+	m.AssertExpectations()
+}
+
+// AssertExpectations reports every expectation that hasn't received the
+// expected number of requests
+func (m *MockServer) AssertExpectations() {
+	m.t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expectations {
+		if !e.satisfied() {
+			m.t.Errorf("civogo mock: expected %s to be called %s, but it was called %d time(s)", e, e.timesString(), e.calls)
+		}
+	}
+}
+
+// WithQuery requires the request to have the query parameter key set to value,
+// other query parameters (such as region) are ignored
+func (e *MockExpectation) WithQuery(key, value string) *MockExpectation {
+	e.query[key] = value
+	return e
+}
+
+// WithBody requires the request body to match body, JSON bodies are compared
+// semantically so key order and whitespace don't matter
+func (e *MockExpectation) WithBody(body string) *MockExpectation {
+	e.body = body
+	e.matchBody = true
+	return e
+}
+
+// RespondWith sets the status code and body sent back for this expectation
+func (e *MockExpectation) RespondWith(statusCode int, body string) *MockExpectation {
+	e.statusCode = statusCode
+	e.responseBody = body
+	return e
+}
+
+// WithResponseHeader adds a header to the response sent for this expectation
+func (e *MockExpectation) WithResponseHeader(key, value string) *MockExpectation {
+	e.responseHeaders[key] = value
+	return e
+}
+
+// Times sets how many requests this expectation must receive, by default an
+// expectation must be called at least once and can be called any number of times
+func (e *MockExpectation) Times(n int) *MockExpectation {
+	e.times = n
+	return e
+}
+
+// String returns a short description of the expected request
+func (e *MockExpectation) String() string {
+	s := e.method + " " + e.path
+	if len(e.query) > 0 {
+		s += fmt.Sprintf(" query=%v", e.query)
+	}
+	if e.matchBody {
+		s += " body=" + strings.TrimSpace(e.body)
+	}
+	return s
+}
+
+func (e *MockExpectation) timesString() string {
+	if e.times == 0 {
+		return "at least once"
+	}
+	return fmt.Sprintf("%d time(s)", e.times)
+}
+
+func (e *MockExpectation) satisfied() bool {
+	if e.times == 0 {
+		return e.calls > 0
+	}
+	return e.calls == e.times
+}
+
+func (e *MockExpectation) exhausted() bool {
+	return e.times != 0 && e.calls >= e.times
+}
+
+func (e *MockExpectation) matches(req *http.Request, body []byte) bool {
+	if e.method != req.Method || e.path != req.URL.Path {
+		return false
+	}
+
+	query := req.URL.Query()
+	for key, value := range e.query {
+		if query.Get(key) != value {
+			return false
+		}
+	}
+
+	if e.matchBody && !bodiesEqual(e.body, string(body)) {
+		return false
+	}
+
+	return true
+}
+
+// bodiesEqual compares two bodies as JSON if both can be decoded, otherwise
+// as trimmed strings
+func bodiesEqual(expected, actual string) bool {
+	var expectedJSON, actualJSON interface{}
+	if json.Unmarshal([]byte(expected), &expectedJSON) == nil && json.Unmarshal([]byte(actual), &actualJSON) == nil {
+		return reflect.DeepEqual(expectedJSON, actualJSON)
+	}
+	return strings.TrimSpace(expected) == strings.TrimSpace(actual)
+}
+
+func (m *MockServer) handle(rw http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		m.t.Errorf("civogo mock: error reading body of %s %s: %v", req.Method, req.URL, err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	m.mu.Lock()
+	e := m.match(req, body)
+	if e != nil {
+		e.calls++
+	}
+	m.mu.Unlock()
+
+	if e == nil {
+		m.t.Errorf("civogo mock: no expectation matched %s %s with body %q", req.Method, req.URL, string(body))
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(`{"result": "failed to find a matching request"}`))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	for key, value := range e.responseHeaders {
+		rw.Header().Set(key, value)
+	}
+	rw.WriteHeader(e.statusCode)
+	rw.Write([]byte(e.responseBody))
+}
+
+func (m *MockServer) match(req *http.Request, body []byte) *MockExpectation {
+	if !m.ordered {
+		for _, e := range m.expectations {
+			if !e.exhausted() && e.matches(req, body) {
+				return e
+			}
+		}
+		return nil
+	}
+
+	// In order, a request may only skip past expectations that are already satisfied
+	for i := m.next; i < len(m.expectations); i++ {
+		e := m.expectations[i]
+		if !e.exhausted() && e.matches(req, body) {
+			m.next = i
+			return e
+		}
+		if !e.satisfied() {
+			return nil
+		}
+	}
+	return nil
+}
+
+// logT reports mock server failures to the standard logger, for the legacy
+// test helpers which don't have access to a testing.T
+type logT struct{}
+
+func (logT) Helper() {}
+
+func (logT) Errorf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
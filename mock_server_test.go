@@ -0,0 +1,153 @@
+package civogo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// recordingT collects the failures reported by a MockServer
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMockServerMatchesMethodPathQueryAndBody(t *testing.T) {
+	g := NewWithT(t)
+
+	mock := NewMockServer(t)
+	defer mock.Close()
+
+	mock.Expect("GET", "/v2/instances/12345").RespondWith(http.StatusOK, `{"id": "12345", "hostname": "foo"}`)
+	mock.Expect("DELETE", "/v2/instances/12345").RespondWith(http.StatusOK, `{"result": "success"}`)
+	mock.Expect("PUT", "/v2/instances/12345/resize").
+		WithQuery("region", "TEST").
+		WithBody(`{"size": "g3.large", "region": "TEST"}`).
+		RespondWith(http.StatusOK, `{"result": "success"}`)
+
+	client, err := mock.Client()
+	g.Expect(err).To(BeNil())
+
+	instance, err := client.GetInstance("12345")
+	g.Expect(err).To(BeNil())
+	g.Expect(instance.Hostname).To(Equal("foo"))
+
+	got, err := client.UpgradeInstance("12345", "g3.large")
+	EnsureSuccessfulSimpleResponse(t, got, err)
+
+	got, err = client.DeleteInstance("12345")
+	EnsureSuccessfulSimpleResponse(t, got, err)
+}
+
+func TestMockServerStatusCodesAndHeaders(t *testing.T) {
+	g := NewWithT(t)
+
+	mock := NewMockServer(t)
+	defer mock.Close()
+
+	mock.Expect("GET", "/v2/instances/12345").
+		RespondWith(http.StatusNotFound, `{"code": "database_instance_find", "reason": "instance not found"}`).
+		WithResponseHeader("X-Request-Id", "abc")
+
+	client, err := mock.Client()
+	g.Expect(err).To(BeNil())
+
+	_, err = client.GetInstance("12345")
+	g.Expect(errors.Is(err, DatabaseInstanceNotFoundError)).To(BeTrue())
+
+	resp, err := http.Get(mock.Server().URL + "/v2/instances/12345")
+	g.Expect(err).To(BeNil())
+	resp.Body.Close()
+	g.Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	g.Expect(resp.Header.Get("X-Request-Id")).To(Equal("abc"))
+	g.Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+}
+
+func TestMockServerReportsUnmatchedRequests(t *testing.T) {
+	g := NewWithT(t)
+
+	rt := &recordingT{}
+	mock := NewMockServer(rt)
+
+	mock.Expect("GET", "/v2/instances/12345").RespondWith(http.StatusOK, `{"id": "12345"}`)
+
+	client, err := mock.Client()
+	g.Expect(err).To(BeNil())
+
+	// The method doesn't match, so the GET response must not be returned
+	_, err = client.DeleteInstance("12345")
+	g.Expect(err).ToNot(BeNil())
+
+	mock.Close()
+
+	g.Expect(rt.errors).To(HaveLen(2))
+	g.Expect(rt.errors[0]).To(ContainSubstring("no expectation matched DELETE /v2/instances/12345"))
+	g.Expect(rt.errors[1]).To(ContainSubstring("expected GET /v2/instances/12345 to be called at least once"))
+}
+
+func TestMockServerTimes(t *testing.T) {
+	g := NewWithT(t)
+
+	rt := &recordingT{}
+	mock := NewMockServer(rt)
+
+	mock.Expect("GET", "/v2/instances/12345").Times(1).RespondWith(http.StatusOK, `{"id": "12345"}`)
+
+	client, err := mock.Client()
+	g.Expect(err).To(BeNil())
+
+	_, err = client.GetInstance("12345")
+	g.Expect(err).To(BeNil())
+
+	_, err = client.GetInstance("12345")
+	g.Expect(err).ToNot(BeNil())
+
+	mock.Close()
+	g.Expect(rt.errors).To(HaveLen(1))
+}
+
+func TestMockServerInOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	rt := &recordingT{}
+	mock := NewMockServer(rt).InOrder()
+
+	mock.Expect("PUT", "/v2/instances/12345/stop").RespondWith(http.StatusOK, `{"result": "success"}`)
+	mock.Expect("PUT", "/v2/instances/12345/resize").RespondWith(http.StatusOK, `{"result": "success"}`)
+	mock.Expect("PUT", "/v2/instances/12345/start").RespondWith(http.StatusOK, `{"result": "success"}`)
+
+	client, err := mock.Client()
+	g.Expect(err).To(BeNil())
+
+	// Resizing before stopping is out of order
+	_, err = client.UpgradeInstance("12345", "g3.large")
+	g.Expect(err).ToNot(BeNil())
+	g.Expect(rt.errors).To(HaveLen(1))
+
+	_, err = client.StopInstance("12345")
+	g.Expect(err).To(BeNil())
+	_, err = client.UpgradeInstance("12345", "g3.large")
+	g.Expect(err).To(BeNil())
+	_, err = client.StartInstance("12345")
+	g.Expect(err).To(BeNil())
+
+	mock.Close()
+	g.Expect(rt.errors).To(HaveLen(1))
+}
+
+func TestBodiesEqual(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(bodiesEqual(`{"a": 1, "b": [1, 2]}`, `{"b":[1,2],"a":1}`)).To(BeTrue())
+	g.Expect(bodiesEqual(`{"a": 1}`, `{"a": "1"}`)).To(BeFalse())
+	g.Expect(bodiesEqual(" plain text\n", "plain text")).To(BeTrue())
+	g.Expect(bodiesEqual(`{"a": 1}`, `not json`)).To(BeFalse())
+}
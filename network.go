@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
 )
 
 // Network represents a private network for instances to connect to
@@ -169,31 +168,10 @@ func (c *Client) FindNetwork(search string) (*Network, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Network{}
-
-	for _, value := range networks {
-		if value.Name == search || value.ID == search || value.Label == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) || strings.Contains(value.Label, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(networks, search, c.FindMode,
+		func(n Network) string { return n.ID },
+		func(n Network) []string { return []string{n.Label, n.Name} },
+	)
 }
 
 // RenameNetwork renames an existing private network
@@ -271,31 +249,10 @@ func (c *Client) FindSubnet(search, networkID string) (*Subnet, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Subnet{}
-
-	for _, value := range subnets {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(subnets, search, c.FindMode,
+		func(s Subnet) string { return s.ID },
+		func(s Subnet) []string { return []string{s.Name} },
+	)
 }
 
 // AttachSubnetToInstance attaches a subnet to an instance
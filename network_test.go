@@ -222,8 +222,8 @@ func TestFindNetwork(t *testing.T) {
 	}
 
 	_, err := client.FindNetwork("net")
-	if err.Error() != "MultipleMatchesError: unable to find net because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find net because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find net because there were multiple matches: development (12345), production (67890)" {
+		t.Errorf("Expected %s, got %s", "unable to find net because there were multiple matches: development (12345), production (67890)", err.Error())
 	}
 
 	_, err = client.FindNetwork("missing")
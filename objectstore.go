@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 )
 
 // ObjectStore is the struct for the ObjectStore model
@@ -92,31 +91,10 @@ func (c *Client) FindObjectStore(search string) (*ObjectStore, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := ObjectStore{}
-
-	for _, value := range objectstores.Items {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(objectstores.Items, search, c.FindMode,
+		func(o ObjectStore) string { return o.ID },
+		func(o ObjectStore) []string { return []string{o.Name} },
+	)
 }
 
 // NewObjectStore creates a new objectstore
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 )
 
 // ObjectStoreCredential holds the credential of an object store
@@ -85,31 +84,10 @@ func (c *Client) FindObjectStoreCredential(search string) (*ObjectStoreCredentia
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := ObjectStoreCredential{}
-
-	for _, value := range creds.Items {
-		if value.AccessKeyID == search || value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.AccessKeyID, search) || strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(creds.Items, search, c.FindMode,
+		func(o ObjectStoreCredential) string { return o.ID },
+		func(o ObjectStoreCredential) []string { return []string{o.Name, o.AccessKeyID} },
+	)
 }
 
 // NewObjectStoreCredential creates a new objectstore credential
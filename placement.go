@@ -0,0 +1,286 @@
+package civogo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Valid values for AffinityRule.Type. An affinity rule places the instance on
+// the same host as the instances with any of the rule's tags, an anti-affinity
+// rule keeps it off the hosts they're on. An exclusive rule is a hard
+// requirement, otherwise it's a preference the scheduler may ignore.
+const (
+	AffinityTypeAffinity     = "affinity"
+	AffinityTypeAntiAffinity = "anti-affinity"
+)
+
+// RequireAffinity adds a rule that the instance must share a host with the instances tagged with tags
+func (p *PlacementRule) RequireAffinity(tags ...string) *PlacementRule {
+	return p.addAffinityRule(AffinityTypeAffinity, true, tags)
+}
+
+// PreferAffinity adds a rule that the instance should share a host with the instances tagged with tags
+func (p *PlacementRule) PreferAffinity(tags ...string) *PlacementRule {
+	return p.addAffinityRule(AffinityTypeAffinity, false, tags)
+}
+
+// RequireAntiAffinity adds a rule that the instance mustn't share a host with the instances tagged with tags
+func (p *PlacementRule) RequireAntiAffinity(tags ...string) *PlacementRule {
+	return p.addAffinityRule(AffinityTypeAntiAffinity, true, tags)
+}
+
+// PreferAntiAffinity adds a rule that the instance shouldn't share a host with the instances tagged with tags
+func (p *PlacementRule) PreferAntiAffinity(tags ...string) *PlacementRule {
+	return p.addAffinityRule(AffinityTypeAntiAffinity, false, tags)
+}
+
+// SetNodeSelector adds a label the host the instance is placed on must have
+func (p *PlacementRule) SetNodeSelector(key, value string) *PlacementRule {
+	if p.NodeSelector == nil {
+		p.NodeSelector = map[string]string{}
+	}
+	p.NodeSelector[key] = value
+	return p
+}
+
+// IsEmpty returns true if the rule doesn't constrain placement at all
+func (p *PlacementRule) IsEmpty() bool {
+	return len(p.AffinityRules) == 0 && len(p.NodeSelector) == 0
+}
+
+func (p *PlacementRule) addAffinityRule(ruleType string, exclusive bool, tags []string) *PlacementRule {
+	p.AffinityRules = append(p.AffinityRules, AffinityRule{Type: ruleType, Exclusive: exclusive, Tags: tags})
+	return p
+}
+
+// Validate returns PlacementRuleInvalidError listing every problem with the rule:
+// unknown types, rules without tags, invalid tags and tags which are both
+// affine and anti-affine
+func (p *PlacementRule) Validate() error {
+	problems := []string{}
+	antiAffine := map[string]bool{}
+
+	for i, rule := range p.AffinityRules {
+		if rule.Type != AffinityTypeAffinity && rule.Type != AffinityTypeAntiAffinity {
+			problems = append(problems, fmt.Sprintf("affinity rule %d has type %q, it must be %q or %q", i+1, rule.Type, AffinityTypeAffinity, AffinityTypeAntiAffinity))
+		}
+		if len(rule.Tags) == 0 {
+			problems = append(problems, fmt.Sprintf("affinity rule %d has no tags", i+1))
+		}
+		for _, tag := range rule.Tags {
+			if err := validateInstanceTags([]string{tag}); err != nil {
+				problems = append(problems, fmt.Sprintf("affinity rule %d has an invalid tag %q", i+1, tag))
+				continue
+			}
+			if rule.Type == AffinityTypeAntiAffinity {
+				antiAffine[tag] = true
+			}
+		}
+	}
+	reported := map[string]bool{}
+	for _, rule := range p.AffinityRules {
+		for _, tag := range rule.Tags {
+			if rule.Type == AffinityTypeAffinity && antiAffine[tag] && !reported[tag] {
+				problems = append(problems, fmt.Sprintf("tag %q is used for both affinity and anti-affinity", tag))
+				reported[tag] = true
+			}
+		}
+	}
+	for key := range p.NodeSelector {
+		if strings.TrimSpace(key) == "" {
+			problems = append(problems, "node selector has an empty key")
+		}
+	}
+
+	if len(problems) > 0 {
+		err := fmt.Errorf("%s", strings.Join(problems, ", "))
+		return PlacementRuleInvalidError.wrap(err)
+	}
+	return nil
+}
+
+// ValidateTags returns PlacementRuleInvalidError if a tag in any of the rules
+// doesn't match any of instances, as the rule would have nothing to apply to
+func (p *PlacementRule) ValidateTags(instances []Instance) error {
+	problems := []string{}
+	for i, rule := range p.AffinityRules {
+		for _, tag := range rule.Tags {
+			if len(filterInstancesByTag(instances, tag)) == 0 {
+				problems = append(problems, fmt.Sprintf("affinity rule %d refers to tag %q which no instance has", i+1, tag))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		err := fmt.Errorf("%s", strings.Join(problems, ", "))
+		return PlacementRuleInvalidError.wrap(err)
+	}
+	return nil
+}
+
+// ValidatePlacementRule validates rule, checking the tags in its rules are on current instances
+func (c *Client) ValidatePlacementRule(rule *PlacementRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	instances, err := c.ListAllInstances()
+	if err != nil {
+		return decodeError(err)
+	}
+	return rule.ValidateTags(instances)
+}
+
+// PlacementConflict is a reason SimulatePlacement predicts an instance can't be
+// placed as its rule asks, because of the instance named
+type PlacementConflict struct {
+	InstanceID string
+	Hostname   string
+	Reason     string
+	// Hard is true when both rules involved are exclusive, so the instance
+	// can't be placed, otherwise only a preference will be ignored
+	Hard bool
+}
+
+// PlacementSimulation is SimulatePlacement's prediction
+type PlacementSimulation struct {
+	Conflicts []PlacementConflict
+}
+
+// OK returns true if there are no hard conflicts
+func (s *PlacementSimulation) OK() bool {
+	return s.Err() == nil
+}
+
+// Err returns PlacementConflictError describing the hard conflicts, or nil if there aren't any
+func (s *PlacementSimulation) Err() error {
+	reasons := []string{}
+	for _, conflict := range s.Conflicts {
+		if conflict.Hard {
+			reasons = append(reasons, conflict.Reason)
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("%s", strings.Join(reasons, ", "))
+	return PlacementConflictError.wrap(err)
+}
+
+// SimulatePlacement predicts whether an instance with the given tags and
+// placement rule conflicts with the rules of the existing instances. The API
+// doesn't say which host instances are on, so it's a prediction from the rules
+// alone, for example requiring affinity with an instance which requires
+// anti-affinity with the new instance's tags.
+func SimulatePlacement(rule PlacementRule, tags []string, instances []Instance) *PlacementSimulation {
+	simulation := &PlacementSimulation{Conflicts: []PlacementConflict{}}
+	conflict := func(instance Instance, hard bool, format string, args ...interface{}) {
+		simulation.Conflicts = append(simulation.Conflicts, PlacementConflict{
+			InstanceID: instance.ID,
+			Hostname:   instance.Hostname,
+			Reason:     fmt.Sprintf("instance %s ", instance.Hostname) + fmt.Sprintf(format, args...),
+			Hard:       hard,
+		})
+	}
+
+	for _, affinity := range rule.AffinityRules {
+		if affinity.Type != AffinityTypeAffinity {
+			continue
+		}
+		partners := affinityPartners(affinity, instances)
+
+		for _, instance := range partners {
+			// Required to be with an instance it's also required to be apart from
+			for _, anti := range rule.AffinityRules {
+				if anti.Type == AffinityTypeAntiAffinity && affinityRuleMatches(anti, instance) {
+					conflict(instance, affinity.Exclusive && anti.Exclusive, "matches both affinity tags %s and anti-affinity tags %s",
+						strings.Join(affinity.Tags, ","), strings.Join(anti.Tags, ","))
+				}
+			}
+
+			// The partner keeps instances with these tags off its host
+			for _, theirs := range instance.PlacementRule.AffinityRules {
+				if theirs.Type == AffinityTypeAntiAffinity && affinityRuleMatches(theirs, Instance{Tags: tags}) {
+					conflict(instance, affinity.Exclusive && theirs.Exclusive, "has anti-affinity with tags %s, which the new instance has",
+						strings.Join(theirs.Tags, ","))
+				}
+			}
+
+			// The partner's host can't satisfy the new instance's node selector
+			for key, value := range rule.NodeSelector {
+				if theirs, ok := instance.PlacementRule.NodeSelector[key]; ok && theirs != value {
+					conflict(instance, affinity.Exclusive, "is on a node selected by %s=%s, not %s=%s", key, theirs, key, value)
+				}
+			}
+		}
+
+		// Partners which have to be on different hosts can't all share one with the new instance
+		for i, a := range partners {
+			for _, b := range partners[i+1:] {
+				if requiresAntiAffinity(a, b) || requiresAntiAffinity(b, a) {
+					conflict(b, affinity.Exclusive, "can't share a host with instance %s, so the new instance can't be with both", a.Hostname)
+				}
+			}
+		}
+	}
+
+	// Instances which require the new instance's company can't be kept apart from it
+	for _, anti := range rule.AffinityRules {
+		if anti.Type != AffinityTypeAntiAffinity {
+			continue
+		}
+		for _, instance := range affinityPartners(anti, instances) {
+			for _, theirs := range instance.PlacementRule.AffinityRules {
+				if theirs.Type == AffinityTypeAffinity && affinityRuleMatches(theirs, Instance{Tags: tags}) {
+					conflict(instance, anti.Exclusive && theirs.Exclusive, "has affinity with tags %s, which the new instance has",
+						strings.Join(theirs.Tags, ","))
+				}
+			}
+		}
+	}
+
+	return simulation
+}
+
+// SimulateInstancePlacement runs SimulatePlacement for config against the current instances
+func (c *Client) SimulateInstancePlacement(config *InstanceConfig) (*PlacementSimulation, error) {
+	instances, err := c.ListAllInstances()
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	tags := config.Tags
+	if len(tags) == 0 {
+		tags = strings.Fields(config.TagsList)
+	}
+	return SimulatePlacement(config.PlacementRule, tags, instances), nil
+}
+
+func affinityRuleMatches(rule AffinityRule, instance Instance) bool {
+	for _, tag := range rule.Tags {
+		if instance.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func affinityPartners(rule AffinityRule, instances []Instance) []Instance {
+	partners := []Instance{}
+	for _, instance := range instances {
+		if affinityRuleMatches(rule, instance) {
+			partners = append(partners, instance)
+		}
+	}
+	return partners
+}
+
+// requiresAntiAffinity returns true if a has an exclusive anti-affinity rule matching b
+func requiresAntiAffinity(a, b Instance) bool {
+	for _, rule := range a.PlacementRule.AffinityRules {
+		if rule.Type == AffinityTypeAntiAffinity && rule.Exclusive && affinityRuleMatches(rule, b) {
+			return true
+		}
+	}
+	return false
+}
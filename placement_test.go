@@ -0,0 +1,97 @@
+package civogo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPlacementRuleBuilder(t *testing.T) {
+	g := NewWithT(t)
+
+	rule := &PlacementRule{}
+	rule.RequireAntiAffinity("role=db").PreferAffinity("cache").SetNodeSelector("gpu", "a100")
+	g.Expect(rule.Validate()).To(Succeed())
+
+	out, _ := json.Marshal(rule)
+	g.Expect(string(out)).To(Equal(`{"affinity_rules":[{"type":"anti-affinity","exclusive":true,"tags":["role=db"]},{"type":"affinity","exclusive":false,"tags":["cache"]}],"node_selector":{"gpu":"a100"}}`))
+	g.Expect(rule.IsEmpty()).To(BeFalse())
+	g.Expect((&PlacementRule{}).IsEmpty()).To(BeTrue())
+}
+
+func TestPlacementRuleValidate(t *testing.T) {
+	g := NewWithT(t)
+
+	rule := &PlacementRule{AffinityRules: []AffinityRule{{Type: "near", Tags: []string{"web"}}, {Type: AffinityTypeAffinity}}}
+	rule.RequireAffinity("role=web", "bad tag").RequireAntiAffinity("role=web")
+	err := rule.Validate()
+	g.Expect(errors.Is(err, PlacementRuleInvalidError)).To(BeTrue())
+	g.Expect(err.Error()).To(Equal(`PlacementRuleInvalidError: affinity rule 1 has type "near", it must be "affinity" or "anti-affinity", ` +
+		`affinity rule 2 has no tags, affinity rule 3 has an invalid tag "bad tag", tag "role=web" is used for both affinity and anti-affinity`))
+
+	instances := []Instance{{ID: "1", Hostname: "web1", Tags: []string{"role=web"}}}
+	rule = &PlacementRule{}
+	rule.RequireAffinity("role").PreferAntiAffinity("missing").RequireAntiAffinity("role=db")
+	err = rule.ValidateTags(instances)
+	g.Expect(err).To(MatchError(`PlacementRuleInvalidError: affinity rule 2 refers to tag "missing" which no instance has, ` +
+		`affinity rule 3 refers to tag "role=db" which no instance has`))
+}
+
+func TestValidatePlacementRuleAgainstAPI(t *testing.T) {
+	g := NewWithT(t)
+
+	client, server, _ := NewClientForTesting(map[string]string{
+		"/v2/instances": `{"page": 1, "per_page": 20, "pages": 1, "items": [{"id": "1", "hostname": "db1", "tags": ["role=db"]}]}`,
+	})
+	defer server.Close()
+
+	g.Expect(client.ValidatePlacementRule((&PlacementRule{}).RequireAntiAffinity("role=db"))).To(Succeed())
+	err := client.ValidatePlacementRule((&PlacementRule{}).RequireAntiAffinity("role=cache"))
+	g.Expect(errors.Is(err, PlacementRuleInvalidError)).To(BeTrue())
+}
+
+func TestSimulatePlacement(t *testing.T) {
+	g := NewWithT(t)
+
+	db1 := Instance{ID: "1", Hostname: "db1", Tags: []string{"role=db", "zone=a"}}
+	db1.PlacementRule.RequireAntiAffinity("role=db").SetNodeSelector("disk", "nvme")
+	db2 := Instance{ID: "2", Hostname: "db2", Tags: []string{"role=db"}}
+	db2.PlacementRule.RequireAntiAffinity("role=db")
+	app := Instance{ID: "3", Hostname: "app", Tags: []string{"role=app"}}
+	app.PlacementRule.RequireAffinity("role=cache")
+	instances := []Instance{db1, db2, app}
+
+	// Nothing conflicts with a cache near the app
+	simulation := SimulatePlacement(*(&PlacementRule{}).RequireAffinity("role=app"), []string{"role=cache"}, instances)
+	g.Expect(simulation.OK()).To(BeTrue())
+	g.Expect(simulation.Conflicts).To(BeEmpty())
+
+	// Another database can't be placed next to one
+	simulation = SimulatePlacement(*(&PlacementRule{}).RequireAffinity("zone=a"), []string{"role=db"}, instances)
+	g.Expect(simulation.OK()).To(BeFalse())
+	g.Expect(simulation.Err()).To(MatchError("PlacementConflictError: instance db1 has anti-affinity with tags role=db, which the new instance has"))
+
+	// Both databases are on different hosts, so nothing can be with both
+	simulation = SimulatePlacement(*(&PlacementRule{}).RequireAffinity("role=db"), []string{"role=backup"}, instances)
+	g.Expect(simulation.Conflicts).To(HaveLen(1))
+	g.Expect(simulation.Conflicts[0].Reason).To(Equal("instance db2 can't share a host with instance db1, so the new instance can't be with both"))
+
+	// The app requires a cache alongside it, so a cache can't be kept away from it
+	simulation = SimulatePlacement(*(&PlacementRule{}).RequireAntiAffinity("role=app"), []string{"role=cache"}, instances)
+	g.Expect(simulation.Err()).To(MatchError("PlacementConflictError: instance app has affinity with tags role=cache, which the new instance has"))
+
+	// Conflicting node selectors, and a preference rather than a requirement, are soft
+	rule := (&PlacementRule{}).PreferAffinity("zone=a").SetNodeSelector("disk", "ssd")
+	simulation = SimulatePlacement(*rule, []string{"role=web"}, instances)
+	g.Expect(simulation.OK()).To(BeTrue())
+	g.Expect(simulation.Conflicts).To(Equal([]PlacementConflict{
+		{InstanceID: "1", Hostname: "db1", Reason: "instance db1 is on a node selected by disk=nvme, not disk=ssd", Hard: false},
+	}))
+
+	// Required to be with and apart from the same instance
+	rule = (&PlacementRule{}).RequireAffinity("zone=a").RequireAntiAffinity("role=db")
+	simulation = SimulatePlacement(*rule, []string{"role=web"}, instances)
+	g.Expect(simulation.Err()).To(MatchError("PlacementConflictError: instance db1 matches both affinity tags zone=a and anti-affinity tags role=db"))
+}
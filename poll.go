@@ -0,0 +1,48 @@
+package civogo
+
+import (
+	"time"
+)
+
+// poller waits for the API to reach some state, checking every interval until
+// timeout has passed. Zero timings are replaced by the defaults and nil clock
+// functions by the time package's, so workflows built as struct literals work.
+type poller struct {
+	interval        time.Duration
+	timeout         time.Duration
+	defaultInterval time.Duration
+	defaultTimeout  time.Duration
+	sleep           func(time.Duration)
+	now             func() time.Time
+}
+
+// poll calls check until it's done or returns an error. Once the timeout has
+// passed it returns the error from timedOut, wrapped in TimeoutError.
+func (p poller) poll(check func() (bool, error), timedOut func() error) error {
+	interval, timeout := p.interval, p.timeout
+	if interval <= 0 {
+		interval = p.defaultInterval
+	}
+	if timeout <= 0 {
+		timeout = p.defaultTimeout
+	}
+	sleep, now := p.sleep, p.now
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	deadline := now().Add(timeout)
+	for {
+		done, err := check()
+		if done || err != nil {
+			return err
+		}
+		if !now().Before(deadline) {
+			return TimeoutError.wrap(timedOut())
+		}
+		sleep(interval)
+	}
+}
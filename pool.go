@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -67,31 +66,7 @@ func (c *Client) FindKubernetesClusterPool(cid, search string) (*KubernetesPool,
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := KubernetesPool{}
-
-	for _, value := range pools {
-		if value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(pools, search, c.FindMode, func(p KubernetesPool) string { return p.ID }, func(p KubernetesPool) []string { return nil })
 }
 
 // DeleteKubernetesClusterPoolInstance deletes a instance from pool
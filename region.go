@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"strings"
 )
 
 // Region represents a geographical/DC region for Civo resources
@@ -78,35 +76,10 @@ func (c *Client) FindRegion(search string) (*Region, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Region{}
-	search = strings.ToUpper(search)
-
-	for _, value := range allregion {
-		name := strings.ToUpper(value.Name)
-		code := strings.ToUpper(value.Code)
-
-		if name == search || code == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(name, search) || strings.Contains(code, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(allregion, search, c.FindMode,
+		func(r Region) string { return r.Code },
+		func(r Region) []string { return []string{r.Name} },
+	)
 }
 
 // GetDefaultRegion finds the default region for an account
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -106,31 +105,10 @@ func (c *Client) FindSnapshotSchedule(search string) (*SnapshotSchedule, error)
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := SnapshotSchedule{}
-
-	for _, value := range schedules {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(schedules, search, c.FindMode,
+		func(s SnapshotSchedule) string { return s.ID },
+		func(s SnapshotSchedule) []string { return []string{s.Name} },
+	)
 }
 
 // GetSnapshotSchedule retrieves a specific snapshot schedule by ID
@@ -64,9 +64,15 @@ func TestFindSSHKey(t *testing.T) {
 		t.Errorf("Expected %s, got %s", "12345", got.ID)
 	}
 
-	_, err := client.FindSSHKey("23")
-	if err.Error() != "MultipleMatchesError: unable to find 23 because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find 23 because there were multiple matches", err.Error())
+	// A prefix match wins over a match in the middle of another ID
+	got, _ = client.FindSSHKey("23")
+	if got.ID != "233567" {
+		t.Errorf("Expected %s, got %s", "233567", got.ID)
+	}
+
+	_, err := client.FindSSHKey("3")
+	if err.Error() != "MultipleMatchesError: unable to find 3 because there were multiple matches: RSA Key (12345), Test (233567)" {
+		t.Errorf("Expected %s, got %s", "unable to find 3 because there were multiple matches: RSA Key (12345), Test (233567)", err.Error())
 	}
 
 	_, err = client.FindSSHKey("missing")
@@ -3,8 +3,6 @@ package civogo
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
-	"strings"
 	"time"
 )
 
@@ -65,31 +63,10 @@ func (c *Client) FindTeam(search string) (*Team, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Team{}
-
-	for _, value := range teams {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s team because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s team, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(teams, search, c.FindMode,
+		func(t Team) string { return t.ID },
+		func(t Team) []string { return []string{t.Name} },
+	)
 }
 
 // RenameTeam changes the human set name for a team
@@ -127,8 +127,8 @@ func TestFindVolume(t *testing.T) {
 	}
 
 	_, err := client.FindVolume("volume")
-	if err.Error() != "MultipleMatchesError: unable to find volume because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find volume because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find volume because there were multiple matches: my-volume (12345), other-volume (67890)" {
+		t.Errorf("Expected %s, got %s", "unable to find volume because there were multiple matches: my-volume (12345), other-volume (67890)", err.Error())
 	}
 
 	_, err = client.FindVolume("missing")
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
 )
 
 // VPC API provides methods for the VPC umbrella which contains:
@@ -90,31 +89,10 @@ func (c *Client) FindVPCNetwork(search string) (*Network, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Network{}
-
-	for _, value := range networks {
-		if value.Name == search || value.ID == search || value.Label == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) || strings.Contains(value.Label, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(networks, search, c.FindMode,
+		func(n Network) string { return n.ID },
+		func(n Network) []string { return []string{n.Label, n.Name} },
+	)
 }
 
 // RenameVPCNetwork renames an existing private network using VPC API path
@@ -226,31 +204,10 @@ func (c *Client) FindVPCSubnet(search, networkID string) (*Subnet, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Subnet{}
-
-	for _, value := range subnets {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(subnets, search, c.FindMode,
+		func(s Subnet) string { return s.ID },
+		func(s Subnet) []string { return []string{s.Name} },
+	)
 }
 
 // AttachVPCSubnetToInstance attaches a subnet to an instance using VPC API path
@@ -314,31 +271,10 @@ func (c *Client) FindVPCFirewall(search string) (*Firewall, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Firewall{}
-
-	for _, value := range firewalls {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(firewalls, search, c.FindMode,
+		func(f Firewall) string { return f.ID },
+		func(f Firewall) []string { return []string{f.Name} },
+	)
 }
 
 // NewVPCFirewall creates a new firewall record using VPC API path
@@ -425,24 +361,7 @@ func (c *Client) FindVPCFirewallRule(firewallID string, search string) (*Firewal
 		return nil, decodeError(err)
 	}
 
-	found := -1
-
-	for i, firewallRule := range firewallsRules {
-		if strings.Contains(firewallRule.ID, search) {
-			if found != -1 {
-				err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-				return nil, MultipleMatchesError.wrap(err)
-			}
-			found = i
-		}
-	}
-
-	if found == -1 {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
-
-	return &firewallsRules[found], nil
+	return findMatch(firewallsRules, search, c.FindMode, func(r FirewallRule) string { return r.ID }, func(r FirewallRule) []string { return nil })
 }
 
 // DeleteVPCFirewallRule deletes a firewall rule using VPC API path
@@ -496,31 +415,10 @@ func (c *Client) FindVPCLoadBalancer(search string) (*LoadBalancer, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := LoadBalancer{}
-
-	for _, value := range lbs {
-		if value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(lbs, search, c.FindMode,
+		func(l LoadBalancer) string { return l.ID },
+		func(l LoadBalancer) []string { return []string{l.Name} },
+	)
 }
 
 // CreateVPCLoadBalancer creates a new load balancer using VPC API path
@@ -604,31 +502,10 @@ func (c *Client) FindVPCIP(search string) (*IP, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := IP{}
-
-	for _, value := range ips.Items {
-		if value.IP == search || value.Name == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.IP, search) || strings.Contains(value.Name, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(ips.Items, search, c.FindMode,
+		func(i IP) string { return i.ID },
+		func(i IP) []string { return []string{i.Name, i.IP} },
+	)
 }
 
 // NewVPCIP creates a new reserved IP using VPC API path
@@ -177,8 +177,8 @@ func TestFindVPCNetwork(t *testing.T) {
 	}
 
 	_, err := client.FindVPCNetwork("net")
-	if err.Error() != "MultipleMatchesError: unable to find net because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find net because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find net because there were multiple matches: development (12345), production (67890)" {
+		t.Errorf("Expected %s, got %s", "unable to find net because there were multiple matches: development (12345), production (67890)", err.Error())
 	}
 
 	_, err = client.FindVPCNetwork("missing")
@@ -425,8 +425,8 @@ func TestFindVPCFirewall(t *testing.T) {
 	}
 
 	_, err := client.FindVPCFirewall("web")
-	if err.Error() != "MultipleMatchesError: unable to find web because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find web because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find web because there were multiple matches: web-instance (12345), web-node (67789)" {
+		t.Errorf("Expected %s, got %s", "unable to find web because there were multiple matches: web-instance (12345), web-node (67789)", err.Error())
 	}
 
 	_, err = client.FindVPCFirewall("missing")
@@ -618,8 +618,8 @@ func TestFindVPCFirewallRule(t *testing.T) {
 	}
 
 	_, err := client.FindVPCFirewallRule("22", "2")
-	if err.Error() != "MultipleMatchesError: unable to find 2 because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find 2 because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find 2 because there were multiple matches: 21, 22" {
+		t.Errorf("Expected %s, got %s", "unable to find 2 because there were multiple matches: 21, 22", err.Error())
 	}
 
 	_, err = client.FindVPCFirewallRule("22", "missing")
@@ -741,7 +741,7 @@ func TestFindVPCLoadBalancer(t *testing.T) {
 	}
 
 	_, err := client.FindVPCLoadBalancer("lb")
-	if err.Error() != "MultipleMatchesError: unable to find lb because there were multiple matches" {
+	if err.Error() != "MultipleMatchesError: unable to find lb because there were multiple matches: my-lb (12345), other-lb (67890)" {
 		t.Errorf("Expected multiple matches error, got %s", err.Error())
 	}
 
@@ -860,7 +860,7 @@ func TestFindVPCIP(t *testing.T) {
 	}
 
 	_, err := client.FindVPCIP("ip")
-	if err.Error() != "MultipleMatchesError: unable to find ip because there were multiple matches" {
+	if err.Error() != "MultipleMatchesError: unable to find ip because there were multiple matches: my-ip (12345), other-ip (67890)" {
 		t.Errorf("Expected multiple matches error, got %s", err.Error())
 	}
 
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strings"
 )
 
 // Webhook is a representation of a saved webhook callback from changes in Civo
@@ -62,31 +61,10 @@ func (c *Client) FindWebhook(search string) (*Webhook, error) {
 		return nil, decodeError(err)
 	}
 
-	exactMatch := false
-	partialMatchesCount := 0
-	result := Webhook{}
-
-	for _, value := range webhooks {
-		if value.URL == search || value.ID == search {
-			exactMatch = true
-			result = value
-		} else if strings.Contains(value.URL, search) || strings.Contains(value.ID, search) {
-			if !exactMatch {
-				result = value
-				partialMatchesCount++
-			}
-		}
-	}
-
-	if exactMatch || partialMatchesCount == 1 {
-		return &result, nil
-	} else if partialMatchesCount > 1 {
-		err := fmt.Errorf("unable to find %s because there were multiple matches", search)
-		return nil, MultipleMatchesError.wrap(err)
-	} else {
-		err := fmt.Errorf("unable to find %s, zero matches", search)
-		return nil, ZeroMatchesError.wrap(err)
-	}
+	return findMatch(webhooks, search, c.FindMode,
+		func(w Webhook) string { return w.ID },
+		func(w Webhook) []string { return []string{w.URL} },
+	)
 }
 
 // UpdateWebhook updates a webhook
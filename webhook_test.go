@@ -126,8 +126,8 @@ func TestFindWebhook(t *testing.T) {
 	}
 
 	_, err := client.FindWebhook("example")
-	if err.Error() != "MultipleMatchesError: unable to find example because there were multiple matches" {
-		t.Errorf("Expected %s, got %s", "unable to find example because there were multiple matches", err.Error())
+	if err.Error() != "MultipleMatchesError: unable to find example because there were multiple matches: https://my.example.com/webhook (12345), https://other.example.com/webhook (67890)" {
+		t.Errorf("Expected %s, got %s", "unable to find example because there were multiple matches: https://my.example.com/webhook (12345), https://other.example.com/webhook (67890)", err.Error())
 	}
 
 	_, err = client.FindWebhook("missing")